@@ -7,4 +7,5 @@ require (
 	github.com/bluele/gcache v0.0.2
 	github.com/lestrrat-go/strftime v1.0.5
 	go.uber.org/zap v1.19.1
+	gopkg.in/yaml.v3 v3.0.1
 )
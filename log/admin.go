@@ -0,0 +1,226 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// AdminHandler 运行时查看和修改日志级别的http接口
+//
+// GET 列出已注册logger的各输出端级别，可用logger参数只看一个logger
+// PUT/POST 修改级别，参数:
+//
+//	logger 必填，logger名称，见Register
+//	output 选填，输出端名称或下标，为空时修改全部输出端
+//	level  必填，trace debug info warn error fatal
+//	ttl    选填，如10m，到期后自动恢复成修改前的级别，避免忘记调回debug导致日志写满磁盘
+//
+// 输出端热加载替换或者logger重新注册后，新配置的级别生效，之前的自动恢复取消
+type AdminHandler struct {
+	mu      sync.Mutex
+	reverts map[string]*levelRevert // key为logger/output
+}
+
+// levelRevert 待恢复的日志级别
+type levelRevert struct {
+	level  Level
+	at     time.Time
+	timer  *time.Timer
+	logger Logger          // 修改时的logger，重新注册后取消
+	output zap.AtomicLevel // 修改时输出端的级别，热加载替换输出端后取消
+}
+
+// stale logger重新注册或者输出端被热加载替换后，恢复的级别已经不属于当前配置
+func (rv *levelRevert) stale(name, output string) bool {
+	l := Get(name)
+	lvl, _ := outputLevel(l, output)
+	return l != rv.logger || lvl != rv.output
+}
+
+// adminOutput 输出端级别的返回格式
+type adminOutput struct {
+	Name     string     `json:"name"`
+	Writer   string     `json:"writer"`
+	Level    string     `json:"level"`
+	RevertAt *time.Time `json:"revert_at,omitempty"`
+}
+
+// NewAdminHandler 创建日志级别管理http handler，需要业务自己挂载到http server上
+func NewAdminHandler() *AdminHandler {
+	return &AdminHandler{
+		reverts: make(map[string]*levelRevert),
+	}
+}
+
+// ServeHTTP 实现http.Handler
+func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.list(w, r)
+	case http.MethodPut, http.MethodPost:
+		h.set(w, r)
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		writeAdminError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (h *AdminHandler) list(w http.ResponseWriter, r *http.Request) {
+	name := r.FormValue("logger")
+	if name == "" {
+		resp := make(map[string][]adminOutput)
+		for n, l := range getAll() {
+			resp[n] = h.outputs(n, l)
+		}
+		writeAdminJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	l := Get(name)
+	if l == nil {
+		writeAdminError(w, http.StatusNotFound, fmt.Sprintf("logger %s not registered", name))
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, map[string][]adminOutput{name: h.outputs(name, l)})
+}
+
+func (h *AdminHandler) set(w http.ResponseWriter, r *http.Request) {
+	name := r.FormValue("logger")
+	l := Get(name)
+	if l == nil {
+		writeAdminError(w, http.StatusNotFound, fmt.Sprintf("logger %s not registered", name))
+		return
+	}
+
+	level, ok := LevelNames[r.FormValue("level")]
+	if !ok {
+		writeAdminError(w, http.StatusBadRequest, fmt.Sprintf("invalid level %q", r.FormValue("level")))
+		return
+	}
+
+	var ttl time.Duration
+	if s := r.FormValue("ttl"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			writeAdminError(w, http.StatusBadRequest, fmt.Sprintf("invalid ttl %q", s))
+			return
+		}
+		ttl = d
+	}
+
+	outputs := make([]string, 0)
+	if output := r.FormValue("output"); output != "" {
+		o, ok := outputName(l, output)
+		if !ok {
+			writeAdminError(w, http.StatusNotFound, fmt.Sprintf("output %s not found in logger %s", output, name))
+			return
+		}
+		outputs = append(outputs, o)
+	} else {
+		for _, o := range l.Levels() {
+			outputs = append(outputs, o.Name)
+		}
+	}
+
+	for _, output := range outputs {
+		h.setLevel(name, l, output, level, ttl)
+	}
+	writeAdminJSON(w, http.StatusOK, map[string][]adminOutput{name: h.outputs(name, l)})
+}
+
+// setLevel 修改级别，ttl大于0时到期恢复成第一次临时修改前的级别
+func (h *AdminHandler) setLevel(name string, l Logger, output string, level Level, ttl time.Duration) {
+	key := name + "/" + output
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	prev := l.GetLevel(output)
+	if rv, ok := h.reverts[key]; ok {
+		rv.timer.Stop()
+		if !rv.stale(name, output) {
+			prev = rv.level
+		}
+		delete(h.reverts, key)
+	}
+
+	l.SetLevel(output, level)
+	if ttl <= 0 {
+		return
+	}
+
+	lvl, _ := outputLevel(l, output)
+	rv := &levelRevert{level: prev, at: time.Now().Add(ttl), logger: l, output: lvl}
+	rv.timer = time.AfterFunc(ttl, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.reverts[key] != rv { // 已经被后续的修改覆盖
+			return
+		}
+		delete(h.reverts, key)
+		if rv.stale(name, output) { // 热加载后以新配置的级别为准
+			return
+		}
+		l.SetLevel(output, rv.level)
+	})
+	h.reverts[key] = rv
+}
+
+func (h *AdminHandler) outputs(name string, l Logger) []adminOutput {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	levels := l.Levels()
+	outputs := make([]adminOutput, 0, len(levels))
+	for _, o := range levels {
+		level := o.Level
+		out := adminOutput{
+			Name:   o.Name,
+			Writer: o.Writer,
+			Level:  level.String(),
+		}
+		key := name + "/" + o.Name
+		if rv, ok := h.reverts[key]; ok {
+			if rv.stale(name, o.Name) {
+				rv.timer.Stop()
+				delete(h.reverts, key)
+			} else {
+				at := rv.at
+				out.RevertAt = &at
+			}
+		}
+		outputs = append(outputs, out)
+	}
+	return outputs
+}
+
+// outputName 将输出端名称或下标统一成名称，保证同一输出端的自动恢复只有一个
+func outputName(l Logger, output string) (string, bool) {
+	levels := l.Levels()
+	for _, o := range levels {
+		if o.Name == output {
+			return o.Name, true
+		}
+	}
+	i, err := strconv.Atoi(output)
+	if err != nil || i < 0 || i >= len(levels) {
+		return "", false
+	}
+	return levels[i].Name, true
+}
+
+func writeAdminJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeAdminError(w http.ResponseWriter, code int, msg string) {
+	writeAdminJSON(w, code, map[string]string{"error": msg})
+}
@@ -0,0 +1,170 @@
+package log
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newAdminLogger 注册一个有两个输出端的logger
+func newAdminLogger(t *testing.T) (string, Logger) {
+	name := t.Name()
+	named := testOutputConfig(name+"/error", "error")
+	named.Name = "file_error"
+	l := NewZapLog(Config{testOutputConfig(name+"/0", "info"), named})
+	Register(name, l)
+	return name, l
+}
+
+func adminRequest(t *testing.T, h http.Handler, method string, params url.Values) (int, map[string]json.RawMessage) {
+	var r *http.Request
+	if method == http.MethodGet {
+		r = httptest.NewRequest(method, "/log/level?"+params.Encode(), nil)
+	} else {
+		r = httptest.NewRequest(method, "/log/level", strings.NewReader(params.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	resp := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal %q fail:%v", w.Body.String(), err)
+	}
+	return w.Code, resp
+}
+
+func adminOutputs(t *testing.T, raw json.RawMessage) map[string]adminOutput {
+	var outputs []adminOutput
+	if err := json.Unmarshal(raw, &outputs); err != nil {
+		t.Fatalf("unmarshal outputs %s fail:%v", raw, err)
+	}
+	m := make(map[string]adminOutput, len(outputs))
+	for _, o := range outputs {
+		m[o.Name] = o
+	}
+	return m
+}
+
+func TestAdminList(t *testing.T) {
+	name, _ := newAdminLogger(t)
+	h := NewAdminHandler()
+
+	code, resp := adminRequest(t, h, http.MethodGet, url.Values{})
+	if code != http.StatusOK || resp[name] == nil {
+		t.Fatalf("list all got %d %v", code, resp)
+	}
+
+	code, resp = adminRequest(t, h, http.MethodGet, url.Values{"logger": {name}})
+	if code != http.StatusOK || len(resp) != 1 {
+		t.Fatalf("list %s got %d %v", name, code, resp)
+	}
+	outputs := adminOutputs(t, resp[name])
+	if o := outputs["0"]; o.Level != "info" || o.Writer != testWriter || o.RevertAt != nil {
+		t.Errorf("output 0 got %+v", o)
+	}
+	if o := outputs["file_error"]; o.Level != "error" {
+		t.Errorf("output file_error got %+v", o)
+	}
+
+	if code, _ = adminRequest(t, h, http.MethodGet, url.Values{"logger": {"unknown"}}); code != http.StatusNotFound {
+		t.Errorf("list unknown logger got %d", code)
+	}
+	if code, _ = adminRequest(t, h, http.MethodDelete, url.Values{}); code != http.StatusMethodNotAllowed {
+		t.Errorf("delete got %d", code)
+	}
+}
+
+func TestAdminSet(t *testing.T) {
+	name, l := newAdminLogger(t)
+	h := NewAdminHandler()
+
+	tests := []struct {
+		method string
+		params url.Values
+		code   int
+		levels [2]Level
+	}{
+		{http.MethodPut, url.Values{"output": {"file_error"}, "level": {"warn"}}, http.StatusOK,
+			[2]Level{LevelInfo, LevelWarn}},
+		{http.MethodPost, url.Values{"output": {"0"}, "level": {"trace"}}, http.StatusOK,
+			[2]Level{LevelTrace, LevelWarn}},
+		{http.MethodPut, url.Values{"level": {"debug"}}, http.StatusOK,
+			[2]Level{LevelDebug, LevelDebug}},
+		{http.MethodPut, url.Values{"level": {"verbose"}}, http.StatusBadRequest,
+			[2]Level{LevelDebug, LevelDebug}},
+		{http.MethodPut, url.Values{"level": {"info"}, "ttl": {"ten minutes"}}, http.StatusBadRequest,
+			[2]Level{LevelDebug, LevelDebug}},
+		{http.MethodPut, url.Values{"output": {"file_info"}, "level": {"info"}}, http.StatusNotFound,
+			[2]Level{LevelDebug, LevelDebug}},
+		{http.MethodPut, url.Values{"output": {"2"}, "level": {"info"}}, http.StatusNotFound,
+			[2]Level{LevelDebug, LevelDebug}},
+	}
+	for _, tt := range tests {
+		params := url.Values{"logger": {name}}
+		for k, v := range tt.params {
+			params[k] = v
+		}
+		code, resp := adminRequest(t, h, tt.method, params)
+		if code != tt.code {
+			t.Errorf("%s %v got %d %v, want %d", tt.method, tt.params, code, resp, tt.code)
+		}
+		if got := [2]Level{l.GetLevel("0"), l.GetLevel("file_error")}; got != tt.levels {
+			t.Errorf("%s %v got levels %v, want %v", tt.method, tt.params, got, tt.levels)
+		}
+	}
+
+	code, _ := adminRequest(t, h, http.MethodPut, url.Values{"logger": {"unknown"}, "level": {"info"}})
+	if code != http.StatusNotFound {
+		t.Errorf("set unknown logger got %d", code)
+	}
+}
+
+func TestAdminRevert(t *testing.T) {
+	name, l := newAdminLogger(t)
+	h := NewAdminHandler()
+
+	set := func(level, ttl string) map[string]adminOutput {
+		code, resp := adminRequest(t, h, http.MethodPut,
+			url.Values{"logger": {name}, "output": {"0"}, "level": {level}, "ttl": {ttl}})
+		if code != http.StatusOK {
+			t.Fatalf("set %s ttl %s got %d %v", level, ttl, code, resp)
+		}
+		return adminOutputs(t, resp[name])
+	}
+
+	outputs := set("debug", "1h")
+	if outputs["0"].RevertAt == nil || outputs["file_error"].RevertAt != nil {
+		t.Errorf("revert_at got %+v", outputs)
+	}
+
+	// 再次临时修改时恢复成第一次修改前的级别
+	set("trace", "50ms")
+	if lv := l.GetLevel("0"); lv != LevelTrace {
+		t.Fatalf("level %s, want trace", lv.String())
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for l.GetLevel("0") != LevelInfo && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if lv := l.GetLevel("0"); lv != LevelInfo {
+		t.Errorf("level after ttl %s, want info", lv.String())
+	}
+	_, resp := adminRequest(t, h, http.MethodGet, url.Values{"logger": {name}})
+	if o := adminOutputs(t, resp[name])["0"]; o.RevertAt != nil {
+		t.Errorf("revert_at after ttl got %v", o.RevertAt)
+	}
+
+	// 不带ttl的修改取消之前的自动恢复
+	set("debug", "50ms")
+	set("warn", "")
+	time.Sleep(100 * time.Millisecond)
+	if lv := l.GetLevel("0"); lv != LevelWarn {
+		t.Errorf("level %s, want warn", lv.String())
+	}
+}
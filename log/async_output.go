@@ -0,0 +1,85 @@
+package log
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hust-tianbo/go_lib/log/rollwriter"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// OutputStats 异步写入的输出端的统计，热加载后重新开始计数
+type OutputStats struct {
+	Name         string // 输出端名称，没有配置时为输出端下标
+	Writer       string // 输出端类型
+	Accepted     uint64 // 放入队列的日志条数
+	Dropped      uint64 // 队列满时丢弃的日志条数
+	BytesWritten uint64 // 写入文件的字节数
+	Flushes      uint64 // 写入文件的次数
+	Spilled      uint64 // 写入溢出文件的日志条数
+}
+
+// StatsReporter 可以获取异步写入统计的Logger，NewZapLog创建的Logger都实现了该接口
+type StatsReporter interface {
+	// Stats 获取异步写入的输出端的写入和丢弃统计
+	Stats() []OutputStats
+}
+
+// Stats 获取已注册的logger中异步写入的输出端的统计，logger不存在或者不支持时返回nil
+func Stats(name string) []OutputStats {
+	if r, ok := Get(name).(StatsReporter); ok {
+		return r.Stats()
+	}
+	return nil
+}
+
+// statsWriter 可以获取异步写入统计的writer，如rollwriter.AsyncRollWriter
+type statsWriter interface {
+	Stats() rollwriter.AsyncStats
+}
+
+// levelToPriority 日志级别对应的异步写入优先级，队列快满时先丢弃debug，再丢弃info，warn及以上不丢弃
+func levelToPriority(level zapcore.Level) rollwriter.Priority {
+	switch {
+	case level < zapcore.InfoLevel:
+		return rollwriter.PriorityLow
+	case level < zapcore.WarnLevel:
+		return rollwriter.PriorityNormal
+	default:
+		return rollwriter.PriorityHigh
+	}
+}
+
+// asyncOutput 将rollwriter.AsyncRollWriter适配成levelWriter，按级别对应的优先级写入
+type asyncOutput struct {
+	w *rollwriter.AsyncRollWriter
+}
+
+// WriteLevel 按级别对应的优先级写入
+func (o *asyncOutput) WriteLevel(level zapcore.Level, p []byte) (int, error) {
+	return o.w.WritePriority(p, levelToPriority(level))
+}
+
+// Sync 等待队列中的日志全部写入
+func (o *asyncOutput) Sync() error {
+	return o.w.Sync()
+}
+
+// newDropNotice 使用输出端的格式生成丢弃日志的提示
+func newDropNotice(c *OutputConfig) func(dropped uint64) []byte {
+	enc := newEncoder(c) // 只在写日志的协程中调用，不需要加锁
+	return func(dropped uint64) []byte {
+		ent := zapcore.Entry{
+			Level:   zapcore.WarnLevel,
+			Time:    time.Now(),
+			Message: fmt.Sprintf("%d log entries dropped since last notice", dropped),
+		}
+		buf, err := enc.EncodeEntry(ent, nil)
+		if err != nil {
+			return nil
+		}
+		defer buf.Free()
+		return append([]byte(nil), buf.Bytes()...)
+	}
+}
@@ -0,0 +1,80 @@
+package log
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hust-tianbo/go_lib/log/rollwriter"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLevelToPriority(t *testing.T) {
+	tests := []struct {
+		level    zapcore.Level
+		priority rollwriter.Priority
+	}{
+		{zapTraceLevel, rollwriter.PriorityLow},
+		{zapcore.DebugLevel, rollwriter.PriorityLow},
+		{zapcore.InfoLevel, rollwriter.PriorityNormal},
+		{zapcore.WarnLevel, rollwriter.PriorityHigh},
+		{zapcore.ErrorLevel, rollwriter.PriorityHigh},
+		{zapcore.FatalLevel, rollwriter.PriorityHigh},
+	}
+	for _, tt := range tests {
+		if got := levelToPriority(tt.level); got != tt.priority {
+			t.Errorf("level %s priority %d, want %d", tt.level, got, tt.priority)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log_stats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := t.Name()
+	async := OutputConfig{Name: "async", Writer: OutputFile, Level: "debug",
+		WriteConfig: WriteConfig{Filename: filepath.Join(dir, "async.log"), WriteMode: WriteAsync}}
+	syncOut := OutputConfig{Name: "sync", Writer: OutputFile, Level: "debug",
+		WriteConfig: WriteConfig{Filename: filepath.Join(dir, "sync.log"), WriteMode: WriteSync}}
+	l := NewZapLog(Config{async, syncOut})
+	Register(name, l)
+	defer closeAll(l.(*zapLog).holder.load().closers)
+
+	for i := 0; i < 10; i++ {
+		l.Info("stats")
+	}
+	if err := l.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 只有异步写入的输出端有统计
+	stats := Stats(name)
+	if len(stats) != 1 {
+		t.Fatalf("got stats %+v", stats)
+	}
+	st := stats[0]
+	if st.Name != "async" || st.Writer != OutputFile || st.Accepted != 10 || st.Dropped != 0 || st.BytesWritten == 0 {
+		t.Errorf("stats %+v", st)
+	}
+	if Stats("unknown") != nil {
+		t.Error("stats of unknown logger should be nil")
+	}
+}
+
+func TestDropNoticeFormat(t *testing.T) {
+	notice := newDropNotice(&OutputConfig{Formatter: "json"})(3)
+	entry := make(map[string]interface{})
+	if err := json.Unmarshal(notice, &entry); err != nil {
+		t.Fatalf("unmarshal %q fail:%v", notice, err)
+	}
+	if entry["L"] != "WARN" || entry["M"] != "3 log entries dropped since last notice" {
+		t.Errorf("notice %v", entry)
+	}
+}
@@ -4,6 +4,9 @@ package log
 type Config []OutputConfig
 
 type OutputConfig struct {
+	// Name 输出端名称，用于SetLevel/GetLevel按名称定位输出端，不配置时使用输出端下标
+	Name string
+
 	Writer      string
 	WriteConfig WriteConfig `yaml:"writer_config"`
 
@@ -18,6 +21,30 @@ type OutputConfig struct {
 
 	// CallerSkip 控制log函数嵌套深度
 	CallerSkip int `yaml:"caller_skip"`
+
+	// Sampling 日志采样，不配置时不采样
+	Sampling *SamplingConfig `yaml:"sampling"`
+
+	// RateLimit 按打日志的代码位置限流，不配置时不限流
+	RateLimit *RateLimitConfig `yaml:"rate_limit"`
+}
+
+// SamplingConfig 日志采样配置，每个Tick内相同级别相同内容的日志，前Initial条全部输出，之后每Thereafter条输出一条
+type SamplingConfig struct {
+	// Initial 每个周期内全部输出的条数
+	Initial int `yaml:"initial"`
+	// Thereafter 超过Initial后每多少条输出一条
+	Thereafter int `yaml:"thereafter"`
+	// Tick 采样周期，单位ms，默认1000
+	Tick int `yaml:"tick"`
+}
+
+// RateLimitConfig 按代码位置限流配置，令牌桶算法
+type RateLimitConfig struct {
+	// PerSecond 每个代码位置每秒最多输出的条数
+	PerSecond int `yaml:"per_second"`
+	// Burst 允许的突发条数，默认等于PerSecond
+	Burst int `yaml:"burst"`
 }
 
 type TimeSplit string
@@ -54,6 +81,7 @@ func (t TimeSplit) Format() string {
 const (
 	OutputConsole = "console"
 	OutputFile    = "file"
+	OutputSyslog  = "syslog"
 )
 
 type WriteConfig struct {
@@ -63,21 +91,75 @@ type WriteConfig struct {
 	Filename string `yaml:"filename"`
 	// WriteMode 日志写入模式 1.同步，2.异步
 	WriteMode int `yaml:"write_mode"`
-	// RollType 文件滚动类型，按大小分割文件，按时间分割文件
+	// OverflowPolicy 异步写入队列满时的处理策略 block block_timeout drop_newest drop_oldest level spill
+	// 不配置时异步写入为block，极速写为drop_newest；level按日志级别丢弃，先丢debug再丢info，warn及以上不丢弃
+	// spill写入本地溢出文件，队列空闲后按顺序重放，既不阻塞也不丢弃
+	OverflowPolicy string `yaml:"overflow_policy"`
+	// OverflowTimeout block_timeout策略的最长阻塞时间，单位ms，默认100
+	OverflowTimeout int `yaml:"overflow_timeout"`
+	// SpillPath spill策略的溢出文件路径，默认为Filename加.spill
+	SpillPath string `yaml:"spill_path"`
+	// WriteRetries 异步写入文件失败后的重试次数，不配置时为3，小于0时不重试
+	WriteRetries int `yaml:"write_retries"`
+	// RetryBackoff 第一次重试前的等待时间，单位ms，之后每次翻倍，默认10
+	RetryBackoff int `yaml:"retry_backoff"`
+	// SpillMaxSize spill策略溢出文件的大小上限，单位MB，超过后丢弃日志，为0时不限制
+	SpillMaxSize int `yaml:"spill_max_size"`
+	// RollType 文件滚动类型，按大小分割文件，按时间分割文件，按时间和大小分割文件
 	RollType string `yaml:"roll_type"`
 	// MaxDay 日志最大保留天数
 	MaxDay int `yaml:"max_day"`
 	// MaxHistory 日志最大历史文件数
 	MaxHistory int `yaml:"max_history"`
+	// MaxTotalSize 当前文件和所有历史文件的总大小上限，单位MB，超过后从最旧的文件开始删除
+	MaxTotalSize int `yaml:"max_total_size"`
+	// MinFreeSpace 磁盘最小剩余空间，单位MB，低于该值时删除历史文件，仍然不足时只写入DegradeLevel及以上级别的日志
+	MinFreeSpace int `yaml:"min_free_space"`
+	// DegradeLevel 磁盘剩余空间不足时仍然写入的最低日志级别，默认warn
+	DegradeLevel string `yaml:"degrade_level"`
 
 	// 是否压缩
 	Compress bool `yaml:"compress"`
 
 	// MaxSize  日志最大大小
-	MaxSize int `yam:"max_size"`
+	MaxSize int `yaml:"max_size"`
+
+	// BackupNaming 超过大小的备份文件命名方式 timestamp shift ascending，默认timestamp，按时间和大小滚动时默认ascending
+	BackupNaming string `yaml:"backup_naming"`
+	// BackupTimeFormat 按时间戳命名备份文件时的strftime格式，默认bk-%Y%m%d-%H%M%S
+	BackupTimeFormat string `yaml:"backup_time_format"`
 
 	// 按时间分割时，作为时间分割文件的时间单位
 	TimeSplit TimeSplit `yaml:"time_split"`
+	// CurrentLink 按时间分割时在Filename维护指向当前文件的软链接，方便tail -F
+	CurrentLink bool `yaml:"current_link"`
+	// ArchiveDir 滚动出的文件关闭后移动到的归档目录，支持strftime格式按日期分目录，清理历史文件时包括归档目录
+	ArchiveDir string `yaml:"archive_dir"`
+
+	// ReopenInterval 检查日志文件是否被删除或移走的间隔，单位ms，默认1000
+	ReopenInterval int `yaml:"reopen_interval"`
+	// Inotify 使用inotify监听日志文件的删除和移动，被删除或移走后立即重新打开，仅linux支持
+	Inotify bool `yaml:"inotify"`
+
+	// Syslog syslog输出端配置
+	Syslog SyslogConfig `yaml:"syslog"`
+}
+
+type SyslogConfig struct {
+	// Network 连接方式 udp tcp unix unixgram，为空时连接本机syslog
+	Network string `yaml:"network"`
+	// Address 地址，unix socket为文件路径
+	Address string `yaml:"address"`
+	// Facility 日志来源 user daemon local0~local7等，默认user
+	Facility string `yaml:"facility"`
+	// AppName 应用名，默认为进程名
+	AppName string `yaml:"app_name"`
+	// Format 消息格式 rfc5424 rfc3164，默认rfc5424
+	Format string `yaml:"format"`
+	// Framing tcp等流式连接的分帧方式 octet_counting non_transparent，默认octet_counting
+	Framing string `yaml:"framing"`
+	// WriteTimeout 单条日志的写入超时时间，单位ms，默认1000，小于0时不设置超时
+	WriteTimeout int `yaml:"write_timeout"`
 }
 
 type FormatConfig struct {
@@ -118,4 +200,6 @@ const (
 	RollBySize = "size"
 	// RollByTime 按时间分割文件
 	RollByTime = "time"
+	// RollByTimeAndSize 按时间分割文件，同一时间段内按MaxSize分割成编号递增的多个文件
+	RollByTimeAndSize = "time_size"
 )
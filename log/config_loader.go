@@ -0,0 +1,90 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YamlNodeDecoder 基于yaml.Node的配置解析，用于Factory.Setup
+type YamlNodeDecoder struct {
+	Node *yaml.Node
+}
+
+// Decode 解析yaml配置
+func (d *YamlNodeDecoder) Decode(conf interface{}) error {
+	if d.Node == nil {
+		return errors.New("yaml node empty")
+	}
+	return d.Node.Decode(conf)
+}
+
+// loadConfigNodes 读取日志配置文件，文件内容为logger名称到输出端列表的映射，如:
+//
+//	default:
+//	  - writer: console
+//	    level: debug
+//	  - name: file_error
+//	    writer: file
+//	    level: error
+//	    writer_config:
+//	      filename: ./log/error.log
+func loadConfigNodes(path string) (map[string]yaml.Node, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]yaml.Node)
+	if err := yaml.Unmarshal(buf, &nodes); err != nil {
+		return nil, fmt.Errorf("parse log config %s fail:%v", path, err)
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("log config %s empty", path)
+	}
+	return nodes, nil
+}
+
+// LoadConfig 读取日志配置文件，返回logger名称到配置的映射
+func LoadConfig(path string) (map[string]Config, error) {
+	nodes, err := loadConfigNodes(path)
+	if err != nil {
+		return nil, err
+	}
+
+	configs := make(map[string]Config, len(nodes))
+	for name := range nodes {
+		node := nodes[name]
+		conf := Config{}
+		if err := (&YamlNodeDecoder{Node: &node}).Decode(&conf); err != nil {
+			return nil, fmt.Errorf("decode logger %s config fail:%v", name, err)
+		}
+		configs[name] = conf
+	}
+	return configs, nil
+}
+
+// SetupFromFile 读取日志配置文件，通过DefaultLogFactory注册其中的每个logger，名称为default的logger会设置为DefaultLogger
+func SetupFromFile(path string) error {
+	nodes, err := loadConfigNodes(path)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		node := nodes[name]
+		if err := DefaultLogFactory.Setup(name, &YamlNodeDecoder{Node: &node}); err != nil {
+			return fmt.Errorf("setup logger %s fail:%v", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package log
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeConfigFile 把配置写到临时文件，返回路径和删除函数
+func writeConfigFile(t *testing.T, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "log_config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "log.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestLoadConfig(t *testing.T) {
+	path, remove := writeConfigFile(t, `
+default:
+  - writer: console
+    level: debug
+  - name: file_error
+    writer: file
+    level: error
+    writer_config:
+      filename: ./log/error.log
+      max_size: 100
+      max_history: 5
+      compress: true
+access:
+  - writer: file
+    formatter: json
+    writer_config:
+      filename: ./log/access.log
+      roll_type: time
+      time_split: hour
+`)
+	defer remove()
+
+	configs, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("load config fail:%v", err)
+	}
+	if len(configs) != 2 || len(configs["default"]) != 2 || len(configs["access"]) != 1 {
+		t.Fatalf("got configs %+v", configs)
+	}
+
+	c := configs["default"][1]
+	if c.Name != "file_error" || c.Writer != OutputFile || c.Level != "error" {
+		t.Errorf("default output 1 got %+v", c)
+	}
+	wc := c.WriteConfig
+	if wc.Filename != "./log/error.log" || wc.MaxSize != 100 || wc.MaxHistory != 5 || !wc.Compress {
+		t.Errorf("default output 1 writer config got %+v", wc)
+	}
+
+	a := configs["access"][0]
+	if a.Formatter != "json" || a.WriteConfig.RollType != RollByTime || a.WriteConfig.TimeSplit != Hour {
+		t.Errorf("access output got %+v", a)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"empty", ""},
+		{"not yaml", "default: [\n"},
+		{"not list", "default:\n  writer: console\n"},
+		{"bad field", "default:\n  - writer_config:\n      max_size: big\n"},
+	}
+	for _, tt := range tests {
+		path, remove := writeConfigFile(t, tt.content)
+		if _, err := LoadConfig(path); err == nil {
+			t.Errorf("%s config should fail", tt.name)
+		}
+		remove()
+	}
+
+	if _, err := LoadConfig(filepath.Join(os.TempDir(), "log_config_not_exist.yaml")); err == nil {
+		t.Error("missing config file should fail")
+	}
+}
+
+func TestSetupFromFile(t *testing.T) {
+	path, remove := writeConfigFile(t, `
+default:
+  - writer: test
+    level: info
+    formatter: json
+    writer_config:
+      filename: TestSetupFromFile/default
+access:
+  - writer: test
+    level: debug
+    formatter: json
+    writer_config:
+      filename: TestSetupFromFile/access
+`)
+	defer remove()
+	defer setDefaultLogger(DefaultLogger)()
+
+	if err := SetupFromFile(path); err != nil {
+		t.Fatalf("setup from file fail:%v", err)
+	}
+
+	access := Get("access")
+	if access == nil || Get("default") != DefaultLogger {
+		t.Fatalf("loggers not registered, access %v default %v", access, Get("default"))
+	}
+	Debug("default debug")
+	Info("default info")
+	access.Debug("access debug")
+
+	if got := getTestBuffer("TestSetupFromFile/default").messages(t); len(got) != 1 || got[0] != "default info" {
+		t.Errorf("default logger got %v", got)
+	}
+	if got := getTestBuffer("TestSetupFromFile/access").messages(t); len(got) != 1 || got[0] != "access debug" {
+		t.Errorf("access logger got %v", got)
+	}
+}
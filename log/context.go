@@ -0,0 +1,42 @@
+package log
+
+import (
+	"context"
+)
+
+// loggerKey context中保存Logger的key
+type loggerKey struct{}
+
+// WithContext 将logger保存到ctx中，返回新的ctx，一般在请求入口处配合WithFields使用
+func WithContext(ctx context.Context, logger Logger) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext 获取ctx中保存的logger，没有则返回DefaultLogger
+func FromContext(ctx context.Context) Logger {
+	if ctx == nil {
+		return DefaultLogger
+	}
+	if l, ok := ctx.Value(loggerKey{}).(Logger); ok && l != nil {
+		return l
+	}
+	return DefaultLogger
+}
+
+// contextLogger 获取ctx中实际打日志的logger
+// ZapLogWrapper 需要取出内部的zapLog，保证调用栈层数和Debug系列函数一致，caller信息能够正确设置
+func contextLogger(ctx context.Context) Logger {
+	switch l := FromContext(ctx).(type) {
+	case *ZapLogWrapper:
+		// 保护 l 或者 l.l 不可为空
+		if l == nil || l.l == nil {
+			return DefaultLogger
+		}
+		return l.l
+	default:
+		return l
+	}
+}
@@ -0,0 +1,46 @@
+package log
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// lowSpaceChecker 可以判断磁盘剩余空间是否不足的输出端，见rollwriter.RollWriter
+type lowSpaceChecker interface {
+	LowSpace() bool
+}
+
+// diskGuardCore 磁盘剩余空间不足时丢弃低于min级别的日志
+type diskGuardCore struct {
+	zapcore.Core
+	guard lowSpaceChecker
+	min   zapcore.Level
+}
+
+func newDiskGuardCore(core zapcore.Core, guard lowSpaceChecker, degradeLevel string) zapcore.Core {
+	min, ok := Levels[degradeLevel]
+	if !ok || degradeLevel == "" {
+		min = zapcore.WarnLevel
+	}
+	return &diskGuardCore{Core: core, guard: guard, min: min}
+}
+
+// Enabled 降级时低级别日志不输出
+func (c *diskGuardCore) Enabled(lvl zapcore.Level) bool {
+	if lvl < c.min && c.guard.LowSpace() {
+		return false
+	}
+	return c.Core.Enabled(lvl)
+}
+
+// With 附加fields
+func (c *diskGuardCore) With(fields []zapcore.Field) zapcore.Core {
+	return &diskGuardCore{Core: c.Core.With(fields), guard: c.guard, min: c.min}
+}
+
+// Check 降级时低级别日志直接丢弃
+func (c *diskGuardCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < c.min && c.guard.LowSpace() {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
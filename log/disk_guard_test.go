@@ -0,0 +1,45 @@
+package log
+
+import (
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type fakeLowSpace struct {
+	low bool
+}
+
+func (f *fakeLowSpace) LowSpace() bool {
+	return f.low
+}
+
+func TestDiskGuardCore(t *testing.T) {
+	tests := []struct {
+		degradeLevel string
+		low          bool
+		want         []string
+	}{
+		{"", false, []string{"debug", "info", "warn", "error"}},
+		{"", true, []string{"warn", "error"}},
+		{"error", true, []string{"error"}},
+		{"info", true, []string{"info", "warn", "error"}},
+		{"unknown", true, []string{"warn", "error"}},
+	}
+	for _, tt := range tests {
+		buf := &testBuffer{}
+		conf := &OutputConfig{Formatter: "json"}
+		core := zapcore.NewCore(newEncoder(conf), buf, zapcore.DebugLevel)
+		l := zap.New(newDiskGuardCore(core, &fakeLowSpace{low: tt.low}, tt.degradeLevel))
+
+		l.Debug("debug")
+		l.Info("info")
+		l.Warn("warn")
+		l.Error("error")
+		if got := buf.messages(t); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("degrade level %q low %v got %v, want %v", tt.degradeLevel, tt.low, got, tt.want)
+		}
+	}
+}
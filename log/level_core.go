@@ -0,0 +1,70 @@
+package log
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// levelWriter 需要知道日志级别的输出端，如syslog需要把级别映射成severity
+type levelWriter interface {
+	WriteLevel(level zapcore.Level, p []byte) (int, error)
+	Sync() error
+}
+
+// levelCore 和zapcore.NewCore类似，写入时把日志级别传给输出端
+type levelCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	out levelWriter
+}
+
+func newLevelCore(enc zapcore.Encoder, out levelWriter, enab zapcore.LevelEnabler) zapcore.Core {
+	return &levelCore{
+		LevelEnabler: enab,
+		enc:          enc,
+		out:          out,
+	}
+}
+
+// With 附加fields
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &levelCore{
+		LevelEnabler: c.LevelEnabler,
+		enc:          c.enc.Clone(),
+		out:          c.out,
+	}
+	for i := range fields {
+		fields[i].AddTo(clone.enc)
+	}
+	return clone
+}
+
+// Check 级别满足时写入
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write 编码后按级别写入输出端
+func (c *levelCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	_, err = c.out.WriteLevel(ent.Level, buf.Bytes())
+	buf.Free()
+	if err != nil {
+		return err
+	}
+	if ent.Level > zapcore.ErrorLevel {
+		// panic和fatal之前尽量保证日志已经写出
+		_ = c.Sync()
+	}
+	return nil
+}
+
+// Sync 刷盘
+func (c *levelCore) Sync() error {
+	return c.out.Sync()
+}
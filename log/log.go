@@ -1,6 +1,10 @@
 package log
 
-var traceEnabled = false
+import (
+	"context"
+	"fmt"
+	"strings"
+)
 
 var DefaultLogger Logger
 
@@ -9,60 +13,24 @@ func SetLogger(logger Logger) {
 }
 
 // Trace logs to TRACE log. Arguments are handled in the manner of fmt.Print.
-/*func Trace(args ...interface{}) {
-	if traceEnabled {
-		DefaultLogger.Trace(args...)
-	}
+func Trace(args ...interface{}) {
+	DefaultLogger.Trace(args...)
 }
 
 // Tracef logs to TRACE log. Arguments are handled in the manner of fmt.Printf.
 func Tracef(format string, args ...interface{}) {
-	if traceEnabled {
-		DefaultLogger.Tracef(format, args...)
-	}
+	DefaultLogger.Tracef(format, args...)
 }
 
 // TraceContext logs to TRACE log. Arguments are handled in the manner of fmt.Print.
 func TraceContext(ctx context.Context, args ...interface{}) {
-	if !traceEnabled {
-		return
-	}
-
-	switch l := codec.Message(ctx).Logger().(type) {
-	case *ZapLogWrapper:
-		// 保护 l 或者 l.l 不可为空
-		if l == nil || l.l == nil {
-			DefaultLogger.Trace(args...)
-			return
-		}
-		l.l.Trace(args...)
-	case Logger:
-		l.Trace(args...)
-	default:
-		DefaultLogger.Trace(args...)
-	}
+	contextLogger(ctx).Trace(args...)
 }
 
 // TraceContextf logs to TRACE log. Arguments are handled in the manner of fmt.Printf.
 func TraceContextf(ctx context.Context, format string, args ...interface{}) {
-	if !traceEnabled {
-		return
-	}
-
-	switch l := codec.Message(ctx).Logger().(type) {
-	case *ZapLogWrapper:
-		// 保护 l 或者 l.l 不可为空
-		if l == nil || l.l == nil {
-			DefaultLogger.Tracef(format, args...)
-			return
-		}
-		l.l.Tracef(format, args...)
-	case Logger:
-		l.Tracef(format, args...)
-	default:
-		DefaultLogger.Tracef(format, args...)
-	}
-}*/
+	contextLogger(ctx).Tracef(format, args...)
+}
 
 // Debug logs to DEBUG log. Arguments are handled in the manner of fmt.Print.
 func Debug(args ...interface{}) {
@@ -115,3 +83,122 @@ func Fatal(args ...interface{}) {
 func Fatalf(format string, args ...interface{}) {
 	DefaultLogger.Fatalf(format, args...)
 }
+
+// Tracew logs to TRACE log. The variadic key-value pairs are treated as structured fields.
+func Tracew(msg string, keysAndValues ...interface{}) {
+	if l, ok := DefaultLogger.(StructuredLogger); ok {
+		l.Tracew(msg, keysAndValues...)
+		return
+	}
+	DefaultLogger.Trace(kvMessage(msg, keysAndValues))
+}
+
+// Debugw logs to DEBUG log. The variadic key-value pairs are treated as structured fields.
+func Debugw(msg string, keysAndValues ...interface{}) {
+	if l, ok := DefaultLogger.(StructuredLogger); ok {
+		l.Debugw(msg, keysAndValues...)
+		return
+	}
+	DefaultLogger.Debug(kvMessage(msg, keysAndValues))
+}
+
+// Infow logs to INFO log. The variadic key-value pairs are treated as structured fields.
+func Infow(msg string, keysAndValues ...interface{}) {
+	if l, ok := DefaultLogger.(StructuredLogger); ok {
+		l.Infow(msg, keysAndValues...)
+		return
+	}
+	DefaultLogger.Info(kvMessage(msg, keysAndValues))
+}
+
+// Warnw logs to WARNING log. The variadic key-value pairs are treated as structured fields.
+func Warnw(msg string, keysAndValues ...interface{}) {
+	if l, ok := DefaultLogger.(StructuredLogger); ok {
+		l.Warnw(msg, keysAndValues...)
+		return
+	}
+	DefaultLogger.Warn(kvMessage(msg, keysAndValues))
+}
+
+// Errorw logs to ERROR log. The variadic key-value pairs are treated as structured fields.
+func Errorw(msg string, keysAndValues ...interface{}) {
+	if l, ok := DefaultLogger.(StructuredLogger); ok {
+		l.Errorw(msg, keysAndValues...)
+		return
+	}
+	DefaultLogger.Error(kvMessage(msg, keysAndValues))
+}
+
+// Fatalw logs to FATAL log. The variadic key-value pairs are treated as structured fields.
+func Fatalw(msg string, keysAndValues ...interface{}) {
+	if l, ok := DefaultLogger.(StructuredLogger); ok {
+		l.Fatalw(msg, keysAndValues...)
+		return
+	}
+	DefaultLogger.Fatal(kvMessage(msg, keysAndValues))
+}
+
+// DebugContext logs to DEBUG log. Arguments are handled in the manner of fmt.Print.
+func DebugContext(ctx context.Context, args ...interface{}) {
+	contextLogger(ctx).Debug(args...)
+}
+
+// DebugContextf logs to DEBUG log. Arguments are handled in the manner of fmt.Printf.
+func DebugContextf(ctx context.Context, format string, args ...interface{}) {
+	contextLogger(ctx).Debugf(format, args...)
+}
+
+// InfoContext logs to INFO log. Arguments are handled in the manner of fmt.Print.
+func InfoContext(ctx context.Context, args ...interface{}) {
+	contextLogger(ctx).Info(args...)
+}
+
+// InfoContextf logs to INFO log. Arguments are handled in the manner of fmt.Printf.
+func InfoContextf(ctx context.Context, format string, args ...interface{}) {
+	contextLogger(ctx).Infof(format, args...)
+}
+
+// WarnContext logs to WARNING log. Arguments are handled in the manner of fmt.Print.
+func WarnContext(ctx context.Context, args ...interface{}) {
+	contextLogger(ctx).Warn(args...)
+}
+
+// WarnContextf logs to WARNING log. Arguments are handled in the manner of fmt.Printf.
+func WarnContextf(ctx context.Context, format string, args ...interface{}) {
+	contextLogger(ctx).Warnf(format, args...)
+}
+
+// ErrorContext logs to ERROR log. Arguments are handled in the manner of fmt.Print.
+func ErrorContext(ctx context.Context, args ...interface{}) {
+	contextLogger(ctx).Error(args...)
+}
+
+// ErrorContextf logs to ERROR log. Arguments are handled in the manner of fmt.Printf.
+func ErrorContextf(ctx context.Context, format string, args ...interface{}) {
+	contextLogger(ctx).Errorf(format, args...)
+}
+
+// FatalContext logs to ERROR log. Arguments are handled in the manner of fmt.Print.
+// that all Fatal logs will exit with os.Exit(1).
+func FatalContext(ctx context.Context, args ...interface{}) {
+	contextLogger(ctx).Fatal(args...)
+}
+
+// FatalContextf logs to ERROR log. Arguments are handled in the manner of fmt.Printf.
+func FatalContextf(ctx context.Context, format string, args ...interface{}) {
+	contextLogger(ctx).Fatalf(format, args...)
+}
+
+// kvMessage 不支持结构化日志的Logger，把key-value拼接到消息后面
+func kvMessage(msg string, keysAndValues []interface{}) string {
+	var b strings.Builder
+	b.WriteString(msg)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i+1 < len(keysAndValues) {
+			fmt.Fprintf(&b, " %v=%v", keysAndValues[i], keysAndValues[i+1])
+		} else {
+			fmt.Fprintf(&b, " %v", keysAndValues[i])
+		}
+	}
+	return b.String()
+}
@@ -3,8 +3,10 @@ package log
 import (
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"path/filepath"
+	"sync"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -13,16 +15,19 @@ import (
 func init() {
 	RegisterWriter(OutputConsole, DefaultConsoleWriterFactory)
 	RegisterWriter(OutputFile, DefaultFileWriterFactory)
+	RegisterWriter(OutputSyslog, DefaultSyslogWriterFactory)
 	DefaultLogger = NewZapLog(defaultConfig)
 }
 
 var (
 	writers = make(map[string]FactoryInterface)
 	logs    = make(map[string]Logger)
+	logsMu  sync.RWMutex
 
 	DefaultLogFactory           = &Factory{}
 	DefaultConsoleWriterFactory = &ConsoleWriterFactory{}
 	DefaultFileWriterFactory    = &FileWriterFactory{}
+	DefaultSyslogWriterFactory  = &SyslogWriterFactory{}
 )
 
 type FactoryInterface interface {
@@ -30,14 +35,30 @@ type FactoryInterface interface {
 }
 
 func Register(name string, logger Logger) {
+	logsMu.Lock()
 	logs[name] = logger
+	logsMu.Unlock()
 }
 
 // 获取句柄
 func Get(name string) Logger {
+	logsMu.RLock()
+	defer logsMu.RUnlock()
 	return logs[name]
 }
 
+// getAll 获取所有已注册的logger
+func getAll() map[string]Logger {
+	logsMu.RLock()
+	defer logsMu.RUnlock()
+
+	all := make(map[string]Logger, len(logs))
+	for name, l := range logs {
+		all[name] = l
+	}
+	return all
+}
+
 func RegisterWriter(name string, writer FactoryInterface) {
 	writers[name] = writer
 }
@@ -99,6 +120,7 @@ type Decoder struct {
 	OutputConfig *OutputConfig
 	Core         zapcore.Core
 	ZapLevel     zap.AtomicLevel
+	Closer       io.Closer // 热加载替换core后用于关闭输出端，没有需要关闭的资源时为nil
 }
 
 // Decode 解析writer配置 复制一份
@@ -182,6 +204,32 @@ func (f *FileWriterFactory) setupConfig(decoder *Decoder) error {
 		conf.WriteConfig.WriteMode = WriteFast // 默认极速写模式，性能更好，日志满丢弃，防止阻塞服务
 	}
 
-	decoder.Core, decoder.ZapLevel = newFileCore(conf)
-	return nil
+	decoder.Core, decoder.ZapLevel, decoder.Closer, err = newFileCore(conf)
+	return err
+}
+
+// SyslogWriterFactory new syslog writer instance
+type SyslogWriterFactory struct {
+}
+
+// Setup 启动加载配置 并注册syslog output writer
+func (f *SyslogWriterFactory) Setup(name string, configDec DecodeInterface) error {
+
+	if configDec == nil {
+		return errors.New("syslog writer decoder empty")
+	}
+
+	decoder, ok := configDec.(*Decoder)
+	if !ok {
+		return errors.New("syslog writer log decoder type invalid")
+	}
+
+	conf := &OutputConfig{}
+	err := decoder.Decode(&conf)
+	if err != nil {
+		return err
+	}
+
+	decoder.Core, decoder.ZapLevel, decoder.Closer, err = newSyslogCore(conf)
+	return err
 }
@@ -0,0 +1,214 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// testWriter 测试用的输出端，日志以json格式写到内存中，按WriteConfig.Filename区分
+const testWriter = "test"
+
+func init() {
+	RegisterWriter(testWriter, &testWriterFactory{})
+}
+
+type testBuffer struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	closed bool
+}
+
+func (b *testBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *testBuffer) Sync() error {
+	return nil
+}
+
+func (b *testBuffer) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return nil
+}
+
+func (b *testBuffer) isClosed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
+}
+
+// entries 解析写入的日志
+func (b *testBuffer) entries(t *testing.T) []map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		entry := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("unmarshal %q fail:%v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// messages 写入的日志内容
+func (b *testBuffer) messages(t *testing.T) []string {
+	msgs := make([]string, 0)
+	for _, e := range b.entries(t) {
+		msgs = append(msgs, fmt.Sprint(e["M"]))
+	}
+	return msgs
+}
+
+var testBuffers = struct {
+	sync.Mutex
+	m map[string]*testBuffer
+}{m: make(map[string]*testBuffer)}
+
+// getTestBuffer 获取最近一次创建的输出端
+func getTestBuffer(filename string) *testBuffer {
+	testBuffers.Lock()
+	defer testBuffers.Unlock()
+	return testBuffers.m[filename]
+}
+
+type testWriterFactory struct {
+}
+
+func (f *testWriterFactory) Setup(name string, configDec DecodeInterface) error {
+	decoder, ok := configDec.(*Decoder)
+	if !ok {
+		return errors.New("test writer log decoder type invalid")
+	}
+
+	conf := &OutputConfig{}
+	if err := decoder.Decode(&conf); err != nil {
+		return err
+	}
+
+	b := &testBuffer{}
+	testBuffers.Lock()
+	testBuffers.m[conf.WriteConfig.Filename] = b
+	testBuffers.Unlock()
+
+	decoder.ZapLevel = zap.NewAtomicLevelAt(Levels[conf.Level])
+	decoder.Core = zapcore.NewCore(newEncoder(conf), b, decoder.ZapLevel)
+	decoder.Closer = b
+	return nil
+}
+
+func testOutputConfig(filename, level string) OutputConfig {
+	return OutputConfig{
+		Writer:      testWriter,
+		Level:       level,
+		Formatter:   "json",
+		WriteConfig: WriteConfig{Filename: filename},
+	}
+}
+
+// newTestLogger 创建只有一个测试输出端的logger
+func newTestLogger(t *testing.T, level string) (Logger, *testBuffer) {
+	filename := t.Name()
+	l := NewZapLog(Config{testOutputConfig(filename, level)})
+	if l == nil {
+		t.Fatal("new logger fail")
+	}
+	return l, getTestBuffer(filename)
+}
+
+// setDefaultLogger 替换DefaultLogger，返回恢复函数
+func setDefaultLogger(l Logger) func() {
+	old := DefaultLogger
+	SetLogger(l)
+	return func() { SetLogger(old) }
+}
+
+// line 调用方的行号
+func line() int {
+	_, _, n, _ := runtime.Caller(1)
+	return n
+}
+
+func TestFromContext(t *testing.T) {
+	l, _ := newTestLogger(t, "debug")
+	var nilLogger Logger
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want Logger
+	}{
+		{"nil ctx", nil, DefaultLogger},
+		{"without logger", context.Background(), DefaultLogger},
+		{"nil logger", WithContext(context.Background(), nilLogger), DefaultLogger},
+		{"with logger", WithContext(context.Background(), l), l},
+		{"nil parent", WithContext(nil, l), l},
+	}
+	for _, tt := range tests {
+		if got := FromContext(tt.ctx); got != tt.want {
+			t.Errorf("%s got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestContextCaller(t *testing.T) {
+	l, buf := newTestLogger(t, "debug")
+	defer setDefaultLogger(l)()
+
+	ctx := WithContext(context.Background(), l.WithFields("uid", "10001"))
+
+	var lines []int
+	InfoContext(ctx, "with fields")
+	lines = append(lines, line()-1)
+	WarnContextf(ctx, "with %s", "format")
+	lines = append(lines, line()-1)
+	DebugContext(context.Background(), "fallback")
+	lines = append(lines, line()-1)
+	Info("default")
+	lines = append(lines, line()-1)
+	l.WithFields("uid", "10002").Info("wrapper")
+	lines = append(lines, line()-1)
+
+	tests := []struct {
+		msg string
+		uid interface{}
+	}{
+		{"with fields", "10001"},
+		{"with format", "10001"},
+		{"fallback", nil},
+		{"default", nil},
+		{"wrapper", "10002"},
+	}
+	entries := buf.entries(t)
+	if len(entries) != len(tests) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(tests))
+	}
+	for i, tt := range tests {
+		e := entries[i]
+		if e["M"] != tt.msg || e["uid"] != tt.uid {
+			t.Errorf("entry %d got %v, want msg %s uid %v", i, e, tt.msg, tt.uid)
+		}
+		if want := fmt.Sprintf("log/log_test.go:%d", lines[i]); e["C"] != want {
+			t.Errorf("%s caller %v, want %s", tt.msg, e["C"], want)
+		}
+	}
+}
@@ -43,6 +43,13 @@ var LevelNames = map[string]Level{
 	"fatal": LevelFatal,
 }
 
+// OutputLevel 输出端的日志级别信息
+type OutputLevel struct {
+	Name   string // 输出端名称，没有配置时为输出端下标
+	Writer string // 输出端类型，console file等
+	Level  Level  // 当前日志级别
+}
+
 // LoggerOptions  log options
 type LoggerOptions struct {
 	LogLevel Level
@@ -86,10 +93,31 @@ type Logger interface {
 	// Applications should take care to call Sync before exiting
 	Sync() error
 
-	// SetLevel 设置输出端日志级别
+	// SetLevel 设置输出端日志级别，output为输出端名称或者下标
 	SetLevel(output string, level Level)
-	// GetLevel 获取输出端日志级别
+	// GetLevel 获取输出端日志级别，output为输出端名称或者下标
 	GetLevel(output string) Level
+	// Levels 获取所有输出端的名称、类型和日志级别
+	Levels() []OutputLevel
 	// WithFields 设置一些业务自定义数据到每条log里:比如uid，imei等 fields 必须kv成对出现
 	WithFields(fields ...string) Logger
 }
+
+// StructuredLogger 支持结构化key-value日志的Logger，NewZapLog创建的Logger都实现了该接口
+// 包级别的Debugw等函数在DefaultLogger不支持时把key-value拼接到消息后面
+type StructuredLogger interface {
+	Logger
+
+	// Tracew logs a message with some additional context to TRACE log. The variadic key-value pairs are treated as structured fields.
+	Tracew(msg string, keysAndValues ...interface{})
+	// Debugw logs a message with some additional context to DEBUG log. The variadic key-value pairs are treated as structured fields.
+	Debugw(msg string, keysAndValues ...interface{})
+	// Infow logs a message with some additional context to INFO log. The variadic key-value pairs are treated as structured fields.
+	Infow(msg string, keysAndValues ...interface{})
+	// Warnw logs a message with some additional context to WARNING log. The variadic key-value pairs are treated as structured fields.
+	Warnw(msg string, keysAndValues ...interface{})
+	// Errorw logs a message with some additional context to ERROR log. The variadic key-value pairs are treated as structured fields.
+	Errorw(msg string, keysAndValues ...interface{})
+	// Fatalw logs a message with some additional context to FATAL log. The variadic key-value pairs are treated as structured fields.
+	Fatalw(msg string, keysAndValues ...interface{})
+}
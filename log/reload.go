@@ -0,0 +1,292 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hust-tianbo/go_lib/log/rollwriter"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// closeDelay 替换core后延迟关闭旧的输出端，等待正在写入的日志完成
+var closeDelay = time.Second
+
+// coreState 一组输出端的core及其级别、需要关闭的资源
+type coreState struct {
+	core    zapcore.Core
+	outputs []zapOutput
+	closers []io.Closer
+}
+
+// release 刷盘后延迟关闭输出端，next复用的输出端不关闭
+func (s *coreState) release(next *coreState) {
+	if s.core != nil {
+		_ = s.core.Sync()
+	}
+
+	closers := make([]io.Closer, 0, len(s.closers))
+	for _, c := range s.closers {
+		if next == nil || !next.hasCloser(c) {
+			closers = append(closers, c)
+		}
+	}
+	time.AfterFunc(closeDelay, func() { closeAll(closers) })
+}
+
+func (s *coreState) hasCloser(c io.Closer) bool {
+	for _, sc := range s.closers {
+		if sc == c {
+			return true
+		}
+	}
+	return false
+}
+
+// reuse 查找配置没有变化的输出端，热加载时直接复用，避免同一个文件同时有两个writer滚动和清理
+func (s *coreState) reuse(c *OutputConfig, used map[int]bool) (zapOutput, bool) {
+	if s == nil {
+		return zapOutput{}, false
+	}
+	for i, o := range s.outputs {
+		if !used[i] && reflect.DeepEqual(o.conf, *c) {
+			used[i] = true
+			return o, true
+		}
+	}
+	return zapOutput{}, false
+}
+
+func closeAll(closers []io.Closer) {
+	for _, c := range closers {
+		if err := c.Close(); err != nil {
+			fmt.Printf("log writer close fail:%v\n", err)
+		}
+	}
+}
+
+// coreHolder 保存当前生效的coreState，支持原子替换
+type coreHolder struct {
+	state atomic.Value
+	mu    sync.Mutex // 热加载串行执行，保证复用的输出端只被一个新的coreState持有
+}
+
+func (h *coreHolder) load() *coreState {
+	return h.state.Load().(*coreState)
+}
+
+func (h *coreHolder) store(s *coreState) {
+	h.state.Store(s)
+}
+
+func (h *coreHolder) swap(s *coreState) *coreState {
+	old, _ := h.state.Load().(*coreState)
+	h.state.Store(s)
+	return old
+}
+
+// outputLevel 获取logger输出端当前的级别，热加载替换输出端后会变化，复用的输出端不变
+func outputLevel(l Logger, output string) (zap.AtomicLevel, bool) {
+	var zl *zapLog
+	switch v := l.(type) {
+	case *zapLog:
+		zl = v
+	case *ZapLogWrapper:
+		zl = v.l
+	}
+	if zl == nil {
+		return zap.AtomicLevel{}, false
+	}
+	o, ok := zl.findOutput(output)
+	return o.level, ok
+}
+
+// swapCore 转发到coreHolder中当前core的zapcore.Core实现
+// With生成的core只记录fields，core替换后重新在新的core上附加fields
+type swapCore struct {
+	holder  *coreHolder
+	fields  []zapcore.Field
+	derived atomic.Value // *derivedCore 缓存附加了fields的core
+}
+
+type derivedCore struct {
+	state *coreState
+	core  zapcore.Core
+}
+
+func (c *swapCore) current() zapcore.Core {
+	state := c.holder.load()
+	if len(c.fields) == 0 {
+		return state.core
+	}
+
+	if d, ok := c.derived.Load().(*derivedCore); ok && d.state == state {
+		return d.core
+	}
+	core := state.core.With(c.fields)
+	c.derived.Store(&derivedCore{state: state, core: core})
+	return core
+}
+
+// Enabled 实现zapcore.LevelEnabler
+func (c *swapCore) Enabled(lvl zapcore.Level) bool {
+	return c.current().Enabled(lvl)
+}
+
+// With 附加fields
+func (c *swapCore) With(fields []zapcore.Field) zapcore.Core {
+	all := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	all = append(all, c.fields...)
+	all = append(all, fields...)
+	return &swapCore{holder: c.holder, fields: all}
+}
+
+// Check 由当前core决定是否写入
+func (c *swapCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return c.current().Check(ent, ce)
+}
+
+// Write 写入当前core
+func (c *swapCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.current().Write(ent, fields)
+}
+
+// Sync 刷盘当前core
+func (c *swapCore) Sync() error {
+	return c.current().Sync()
+}
+
+// reloader 支持热加载的logger
+type reloader interface {
+	Reload(c Config) error
+}
+
+// Reload 使用新的配置替换已注册logger的输出端，logger未注册时通过DefaultLogFactory创建
+func Reload(name string, c Config) error {
+	if r, ok := Get(name).(reloader); ok {
+		return r.Reload(c)
+	}
+
+	return DefaultLogFactory.Setup(name, &configDecoder{conf: c})
+}
+
+// configDecoder 直接使用已经解析好的Config
+type configDecoder struct {
+	conf Config
+}
+
+// Decode 复制一份配置
+func (d *configDecoder) Decode(conf interface{}) error {
+	c, ok := conf.(*Config)
+	if !ok {
+		return fmt.Errorf("decoder config type:%T invalid, not *Config", conf)
+	}
+	*c = append(Config{}, d.conf...)
+	return nil
+}
+
+// ConfigWatcher 定期检查日志配置文件，文件修改后重新加载有变化的logger
+type ConfigWatcher struct {
+	path     string
+	interval time.Duration
+
+	modTime time.Time
+	size    int64
+	configs map[string]Config
+
+	stopCh chan struct{}
+	once   sync.Once
+}
+
+// WatchConfig 开始监听日志配置文件，以当前文件内容为基准，一般在SetupFromFile之后调用
+func WatchConfig(path string, interval time.Duration) (*ConfigWatcher, error) {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	st, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	configs, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &ConfigWatcher{
+		path:     path,
+		interval: interval,
+		modTime:  st.ModTime(),
+		size:     st.Size(),
+		configs:  configs,
+		stopCh:   make(chan struct{}),
+	}
+	go w.watch()
+	return w, nil
+}
+
+// Stop 停止监听
+func (w *ConfigWatcher) Stop() {
+	w.once.Do(func() {
+		close(w.stopCh)
+	})
+}
+
+func (w *ConfigWatcher) watch() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// check 文件修改时间或大小变化时重新加载
+func (w *ConfigWatcher) check() {
+	st, err := os.Stat(w.path)
+	if err != nil {
+		fmt.Printf("[ConfigWatcher]stat log config %s fail:%v\n", w.path, err)
+		return
+	}
+	if st.ModTime().Equal(w.modTime) && st.Size() == w.size {
+		return
+	}
+
+	// 先记录文件状态，配置有误时每个版本只提示一次
+	w.modTime = st.ModTime()
+	w.size = st.Size()
+
+	configs, err := LoadConfig(w.path)
+	if err != nil { // 配置有误时保持原来的配置，等待下次修改
+		fmt.Printf("[ConfigWatcher]load log config fail:%v\n", err)
+		return
+	}
+
+	for name, c := range configs {
+		if old, ok := w.configs[name]; ok && reflect.DeepEqual(old, c) && Get(name) != nil {
+			continue
+		}
+		if err := Reload(name, c); err != nil {
+			fmt.Printf("[ConfigWatcher]reload logger %s fail:%v\n", name, err)
+			continue
+		}
+		w.configs[name] = c
+	}
+}
+
+// ReopenAll 刷新异步写入的缓冲后重新打开所有文件输出端的当前文件，用于配合外部logrotate
+// 需要收到SIGHUP时自动重新打开的可以调用rollwriter.HandleSIGHUP
+func ReopenAll() error {
+	return rollwriter.ReopenAll()
+}
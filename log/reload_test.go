@@ -0,0 +1,199 @@
+package log
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// setCloseDelay 修改替换后关闭旧输出端的延迟，返回恢复函数
+func setCloseDelay(d time.Duration) func() {
+	old := closeDelay
+	closeDelay = d
+	return func() { closeDelay = old }
+}
+
+func waitClosed(b *testBuffer) bool {
+	deadline := time.Now().Add(2 * time.Second)
+	for !b.isClosed() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	return b.isClosed()
+}
+
+func TestReload(t *testing.T) {
+	defer setCloseDelay(10 * time.Millisecond)()
+
+	name := t.Name()
+	keep := testOutputConfig(name+"/keep", "info")
+	keep.Name = "keep"
+	change := testOutputConfig(name+"/change", "info")
+	change.Name = "change"
+
+	l := NewZapLog(Config{keep, change})
+	Register(name, l)
+	fields := l.WithFields("uid", "10001")
+	keepBuf, changeBuf := getTestBuffer(name+"/keep"), getTestBuffer(name+"/change")
+	l.SetLevel("keep", LevelDebug) // 复用的输出端保留运行时修改的级别
+
+	change.Level = "warn"
+	if err := Reload(name, Config{keep, change}); err != nil {
+		t.Fatalf("reload fail:%v", err)
+	}
+	newChangeBuf := getTestBuffer(name + "/change")
+	if newChangeBuf == changeBuf {
+		t.Fatal("changed output should be recreated")
+	}
+	if !waitClosed(changeBuf) {
+		t.Error("replaced output not closed")
+	}
+	if keepBuf.isClosed() || getTestBuffer(name+"/keep") != keepBuf {
+		t.Error("unchanged output should be reused")
+	}
+
+	fields.Debug("debug")
+	fields.Info("info")
+	fields.Warn("warn")
+	if got := keepBuf.messages(t); !reflect.DeepEqual(got, []string{"debug", "info", "warn"}) {
+		t.Errorf("reused output got %v", got)
+	}
+	if got := newChangeBuf.messages(t); !reflect.DeepEqual(got, []string{"warn"}) {
+		t.Errorf("new output got %v", got)
+	}
+	if e := newChangeBuf.entries(t); len(e) != 1 || e[0]["uid"] != "10001" {
+		t.Errorf("fields lost after reload, got %v", e)
+	}
+	if got := []Level{l.GetLevel("keep"), l.GetLevel("change")}; !reflect.DeepEqual(got, []Level{LevelDebug, LevelWarn}) {
+		t.Errorf("levels after reload %v", got)
+	}
+}
+
+func TestReloadInvalid(t *testing.T) {
+	name := t.Name()
+	l := NewZapLog(Config{testOutputConfig(name+"/0", "info")})
+	Register(name, l)
+	buf := getTestBuffer(name + "/0")
+
+	tests := []Config{
+		{},
+		{testOutputConfig(name+"/1", "info"), {Writer: "unknown"}},
+	}
+	for _, c := range tests {
+		if err := Reload(name, c); err == nil {
+			t.Errorf("reload %+v should fail", c)
+		}
+	}
+	// 失败时保持原来的配置，已经新建的输出端关闭
+	if created := getTestBuffer(name + "/1"); created == nil || !created.isClosed() {
+		t.Error("output created by failed reload not closed")
+	}
+	l.Info("still")
+	if got := buf.messages(t); !reflect.DeepEqual(got, []string{"still"}) || buf.isClosed() {
+		t.Errorf("old output got %v closed %v", got, buf.isClosed())
+	}
+}
+
+func TestReloadUnregistered(t *testing.T) {
+	name := t.Name()
+	if err := Reload(name, Config{testOutputConfig(name, "info")}); err != nil {
+		t.Fatalf("reload fail:%v", err)
+	}
+	l := Get(name)
+	if l == nil {
+		t.Fatal("logger not registered by reload")
+	}
+	l.Info("setup")
+	if got := getTestBuffer(name).messages(t); !reflect.DeepEqual(got, []string{"setup"}) {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestReloadCancelRevert(t *testing.T) {
+	defer setCloseDelay(10 * time.Millisecond)()
+
+	name, l := newAdminLogger(t)
+	h := NewAdminHandler()
+	for _, output := range []string{"0", "file_error"} {
+		code, resp := adminRequest(t, h, http.MethodPut,
+			url.Values{"logger": {name}, "output": {output}, "level": {"debug"}, "ttl": {"50ms"}})
+		if code != http.StatusOK {
+			t.Fatalf("set level got %d %v", code, resp)
+		}
+	}
+
+	// 只替换file_error，输出端0复用，自动恢复仍然生效
+	named := testOutputConfig(name+"/error", "warn")
+	named.Name = "file_error"
+	if err := Reload(name, Config{testOutputConfig(name+"/0", "info"), named}); err != nil {
+		t.Fatalf("reload fail:%v", err)
+	}
+	time.Sleep(150 * time.Millisecond)
+
+	if got := []Level{l.GetLevel("0"), l.GetLevel("file_error")}; !reflect.DeepEqual(got, []Level{LevelInfo, LevelWarn}) {
+		t.Errorf("levels after ttl %v, want [info warn]", got)
+	}
+}
+
+func TestConfigWatcher(t *testing.T) {
+	name := t.Name()
+	config := func(level string) string {
+		return name + ":\n  - writer: test\n    level: " + level +
+			"\n    formatter: json\n    writer_config:\n      filename: " + name + "\n"
+	}
+	path, remove := writeConfigFile(t, config("info"))
+	defer remove()
+	if err := SetupFromFile(path); err != nil {
+		t.Fatalf("setup from file fail:%v", err)
+	}
+	l := Get(name)
+
+	w, err := WatchConfig(path, time.Hour)
+	if err != nil {
+		t.Fatalf("watch config fail:%v", err)
+	}
+	defer w.Stop()
+
+	mtime := time.Now()
+	update := func(content string) {
+		mtime = mtime.Add(time.Second)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 没有修改时不重新加载
+	buf := getTestBuffer(name)
+	w.check()
+	if getTestBuffer(name) != buf {
+		t.Error("reload without change")
+	}
+
+	update(config("warn"))
+	w.check()
+	if lv := l.GetLevel("0"); lv != LevelWarn {
+		t.Errorf("level after reload %s, want warn", lv.String())
+	}
+
+	// 配置有误时保持原来的配置，同一个版本不重复加载
+	update(config("warn") + "  - [\n")
+	w.check()
+	if !w.modTime.Equal(mtime) {
+		t.Errorf("broken config version not recorded, modTime %v want %v", w.modTime, mtime)
+	}
+	if lv := l.GetLevel("0"); lv != LevelWarn {
+		t.Errorf("level after broken config %s, want warn", lv.String())
+	}
+
+	update(config("error"))
+	w.check()
+	if lv := l.GetLevel("0"); lv != LevelError {
+		t.Errorf("level after fix %s, want error", lv.String())
+	}
+}
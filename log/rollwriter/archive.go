@@ -0,0 +1,231 @@
+package rollwriter
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// closedFile 待关闭的文件句柄，rotated不为空时为滚动出的文件的路径
+// 编号后移方式下关闭前文件可能已经被改名，处理时按stat重新查找
+type closedFile struct {
+	file    *os.File
+	rotated string
+	stat    os.FileInfo
+}
+
+// WithOnRotate 设置滚动出的文件关闭后的回调，已经压缩和归档时参数为压缩、归档后的路径
+// 回调在清理协程中执行，耗时的操作需要自己异步处理
+func WithOnRotate(f func(oldPath string)) Option {
+	return func(opt *Options) {
+		opt.OnRotate = f
+	}
+}
+
+// WithArchiveDir 设置归档目录，滚动出的文件关闭后移动到该目录，支持strftime格式，如/data/archive/%Y%m%d
+// 按个数、时间和总大小清理时包括归档目录下的文件
+func WithArchiveDir(dir string) Option {
+	return func(opt *Options) {
+		opt.ArchiveDir = dir
+	}
+}
+
+// handleRotated 滚动出的文件关闭后，按配置压缩、移动到归档目录并回调，只在清理协程中调用
+// 压缩和归档期间持有histMu，避免编号后移把文件改名
+func (w *RollWriter) handleRotated(f closedFile) {
+	if w.archive == nil && w.opts.OnRotate == nil { // 只需要压缩的由compressHistory统一处理
+		return
+	}
+
+	w.histMu.Lock()
+	path := w.rotatedPath(f.rotated, f.stat)
+	if w.opts.IfCompress {
+		err := compressFile(path)
+		if err == nil || fileExist(path+compressSuffix) {
+			path += compressSuffix
+		} else {
+			fmt.Fprintf(os.Stderr, "rollwriter: compress %s fail:%v\n", path, err)
+		}
+	}
+
+	if w.archive != nil {
+		dir := w.archive.FormatString(time.Now())
+		dst, err := w.archiveFile(path, dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "rollwriter: archive %s to %s fail:%v\n", path, dir, err)
+		} else {
+			path = dst
+		}
+	}
+	w.histMu.Unlock()
+
+	if w.opts.OnRotate != nil {
+		w.opts.OnRotate(path)
+	}
+}
+
+// rotatedPath 滚动出的文件当前的路径，已经被编号后移改名时按设备号和inode在历史文件中查找，调用方持有histMu
+func (w *RollWriter) rotatedPath(path string, stat os.FileInfo) string {
+	if stat == nil {
+		return path
+	}
+	if st, err := os.Stat(path); err == nil && os.SameFile(st, stat) {
+		return path
+	}
+
+	files, _ := w.getDirHistory()
+	for _, f := range files {
+		if os.SameFile(f.FileInfo, stat) {
+			return filepath.Join(f.dir, f.Name())
+		}
+	}
+	return path
+}
+
+// archiveFile 把文件移动到归档目录，跨设备时复制后删除，返回归档后的路径
+func (w *RollWriter) archiveFile(src, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	dst := w.archiveName(dir, filepath.Base(src))
+	if err := os.Rename(src, dst); err == nil {
+		return dst, nil
+	}
+
+	if err := copyFile(src, dst); err != nil {
+		return "", err
+	}
+	return dst, os.Remove(src)
+}
+
+// archiveName 归档后的路径，数字编号的备份在归档目录中按已有的最大编号递增，
+// 避免当前目录编号重新开始后与归档文件重名或者顺序颠倒，其他文件重名时加.N后缀
+func (w *RollWriter) archiveName(dir, name string) string {
+	ext := ""
+	if strings.HasSuffix(name, compressSuffix) {
+		name, ext = strings.TrimSuffix(name, compressSuffix), compressSuffix
+	}
+
+	if m := w.history.FindStringSubmatch(name); m != nil && w.opts.BackupNaming != BackupNameTimestamp && m[2] != "" {
+		n := 0
+		files, _ := ioutil.ReadDir(dir)
+		for _, f := range files {
+			if fm := w.history.FindStringSubmatch(f.Name()); fm != nil && fm[1] == m[1] {
+				if i, err := strconv.Atoi(fm[2]); err == nil && i > n {
+					n = i
+				}
+			}
+		}
+		if n > 0 {
+			return filepath.Join(dir, m[1]+"."+strconv.Itoa(n+1)+ext)
+		}
+	}
+
+	dst := filepath.Join(dir, name+ext)
+	for i := 1; fileExist(dst); i++ {
+		dst = filepath.Join(dir, name+"."+strconv.Itoa(i)+ext)
+	}
+	return dst
+}
+
+// copyFile 复制文件并保留修改时间，复制完成前不会出现目标文件
+func copyFile(src, dst string) error {
+	st, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, st.Mode())
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if err == nil {
+		err = out.Sync()
+	}
+	if e := out.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	_ = os.Chtimes(tmp, st.ModTime(), st.ModTime())
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// archiveRoot 归档目录中不包含时间格式的部分，清理时从这里开始查找
+func (w *RollWriter) archiveRoot() string {
+	dir := w.opts.ArchiveDir
+	if i := strings.Index(dir, "%"); i >= 0 {
+		dir = filepath.Dir(dir[:i+1])
+	}
+	return filepath.Clean(dir)
+}
+
+// archiveDirs 归档目录下已经生成的目录，只查找时间格式对应的层级，不遍历整个归档根目录
+func (w *RollWriter) archiveDirs() []string {
+	if w.archive == nil {
+		return nil
+	}
+
+	root := w.archiveRoot()
+	depth := 0
+	if rel, err := filepath.Rel(root, filepath.Clean(w.opts.ArchiveDir)); err == nil && rel != "." {
+		depth = len(strings.Split(rel, string(filepath.Separator)))
+	}
+
+	dirs := []string{root}
+	for i := 0; i < depth; i++ {
+		var next []string
+		for _, dir := range dirs {
+			files, err := ioutil.ReadDir(dir)
+			if err != nil {
+				continue
+			}
+			for _, f := range files {
+				if f.IsDir() {
+					next = append(next, filepath.Join(dir, f.Name()))
+				}
+			}
+		}
+		dirs = next
+	}
+	return dirs
+}
+
+// archiveHistory 查找归档目录下与当前文件匹配的历史文件，不包括当前目录
+func (w *RollWriter) archiveHistory() []logWithT {
+	currDir := filepath.Clean(w.currDir)
+	var logWithTs []logWithT
+	for _, dir := range w.archiveDirs() {
+		if filepath.Clean(dir) == currDir {
+			continue
+		}
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		logWithTs = append(logWithTs, w.matchHistory(dir, files)...)
+	}
+	return logWithTs
+}
@@ -0,0 +1,191 @@
+package rollwriter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestArchiveRotated(t *testing.T) {
+	tests := []struct {
+		name     string
+		compress bool
+	}{
+		{"plain", false},
+		{"compress", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer removeAll(dir)
+
+			// 归档目录中已有的编号之后继续递增
+			archive := filepath.Join(dir, "archive")
+			if err := os.MkdirAll(archive, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(filepath.Join(archive, "app.log.5"), []byte("five"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			var mu sync.Mutex
+			var rotated []string
+			w, err := NewRollWriter(filepath.Join(dir, "app.log"), withMaxBytes(3),
+				WithBackupNaming(BackupNameAscending), WithCompress(tt.compress), WithArchiveDir(archive),
+				WithOnRotate(func(path string) {
+					mu.Lock()
+					rotated = append(rotated, path)
+					mu.Unlock()
+				}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range []string{"aaa", "bbb"} {
+				if _, err := w.Write([]byte(s)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil { // 等待滚动出的文件处理完
+				t.Fatal(err)
+			}
+
+			suffix := ""
+			if tt.compress {
+				suffix = compressSuffix
+			}
+			want := []string{filepath.Join(archive, "app.log.6"+suffix), filepath.Join(archive, "app.log.7"+suffix)}
+			if !reflect.DeepEqual(rotated, want) {
+				t.Fatalf("OnRotate got %v, want %v", rotated, want)
+			}
+			for i, content := range []string{"aaa", "bbb"} {
+				got := ""
+				if tt.compress {
+					got = readGzip(t, want[i])
+				} else {
+					got = readFile(t, want[i])
+				}
+				if got != content {
+					t.Errorf("%s content %q, want %q", want[i], got, content)
+				}
+			}
+
+			left, _ := filepath.Glob(filepath.Join(dir, "app.log.*"))
+			if len(left) != 0 {
+				t.Errorf("rotated files left in log dir %v", left)
+			}
+		})
+	}
+}
+
+func TestArchiveDirs(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+
+	root := filepath.Join(dir, "archive")
+	for _, d := range []string{"2026/1015", "2026/1016", "2026/1016/deep", "other"} {
+		if err := os.MkdirAll(filepath.Join(root, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 更深层目录中的文件不属于归档目录，不会被清理
+	deep := filepath.Join(root, "2026/1016/deep/app.log.1")
+	if err := ioutil.WriteFile(deep, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewRollWriter(filepath.Join(dir, "app.log"), WithArchiveDir(filepath.Join(root, "%Y/%m%d")))
+	if err != nil {
+		t.Fatalf("new writer fail:%v", err)
+	}
+	defer w.Close()
+
+	got := w.archiveDirs()
+	sort.Strings(got)
+	want := []string{filepath.Join(root, "2026/1015"), filepath.Join(root, "2026/1016")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("archive dirs %v, want %v", got, want)
+	}
+
+	for _, f := range w.archiveHistory() {
+		if f.dir == filepath.Dir(deep) {
+			t.Errorf("archive history includes %s", deep)
+		}
+	}
+}
+
+func TestRemoveCompressTmp(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+
+	archive := filepath.Join(dir, "archive", "20261016")
+	if err := os.MkdirAll(archive, 0755); err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewRollWriter(filepath.Join(dir, "app.log"),
+		WithArchiveDir(filepath.Join(dir, "archive", "%Y%m%d")))
+	if err != nil {
+		t.Fatalf("new writer fail:%v", err)
+	}
+	defer w.Close()
+
+	tmps := []string{
+		filepath.Join(dir, "app.log.1"+compressTmpSuffix),
+		filepath.Join(archive, "app.log.2"+compressTmpSuffix),
+	}
+	other := filepath.Join(archive, "other.log.1"+compressTmpSuffix)
+	for _, f := range append(tmps, other) {
+		if err := ioutil.WriteFile(f, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w.removeCompressTmp()
+	for _, f := range tmps {
+		if fileExist(f) {
+			t.Errorf("%s not removed", f)
+		}
+	}
+	if !fileExist(other) {
+		t.Errorf("%s of other log removed", other)
+	}
+}
+
+func TestArchiveExpire(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+
+	// 按个数清理时包括归档目录下的文件
+	archive := filepath.Join(dir, "archive", "20261016")
+	if err := os.MkdirAll(archive, 0755); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i, path := range []string{filepath.Join(archive, "app.log.1"), filepath.Join(archive, "app.log.2"),
+		filepath.Join(dir, "app.log.3")} {
+		if err := ioutil.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mt := now.Add(time.Duration(i-10) * time.Hour)
+		_ = os.Chtimes(path, mt, mt)
+	}
+
+	w, err := NewRollWriter(filepath.Join(dir, "app.log"), WithBackupNaming(BackupNameAscending),
+		WithArchiveDir(filepath.Join(dir, "archive", "%Y%m%d")), WithMaxHistory(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.expireFile()
+	if fileExist(filepath.Join(archive, "app.log.1")) {
+		t.Error("oldest archived file not removed")
+	}
+	if !fileExist(filepath.Join(archive, "app.log.2")) || !fileExist(filepath.Join(dir, "app.log.3")) {
+		t.Error("newer files removed")
+	}
+}
@@ -0,0 +1,114 @@
+package rollwriter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultWriteRetries 写入下层writer失败后默认的重试次数
+	defaultWriteRetries = 3
+	// defaultRetryBackoff 第一次重试前的等待时间，之后每次翻倍
+	defaultRetryBackoff = 10 * time.Millisecond
+	// errorReportInterval 没有设置ErrorHandler时输出到stderr的最小间隔
+	errorReportInterval = 10 * time.Second
+)
+
+// WithErrorHandler 设置写入下层writer失败(重试后仍然失败)时的回调，在写日志的协程中调用
+// 不设置时限频输出到stderr
+func WithErrorHandler(f func(err error)) AsyncOption {
+	return func(opts *AsyncOptions) {
+		opts.ErrorHandler = f
+	}
+}
+
+// WithWriteRetry 设置写入下层writer失败后的重试次数和第一次重试前的等待时间，之后每次等待时间翻倍
+// retries为0时使用默认值，小于0时不重试；backoff为0时使用默认值
+func WithWriteRetry(retries int, backoff time.Duration) AsyncOption {
+	return func(opts *AsyncOptions) {
+		if retries < 0 {
+			opts.WriteRetries = 0
+		} else if retries > 0 {
+			opts.WriteRetries = retries
+		}
+		if backoff > 0 {
+			opts.RetryBackoff = backoff
+		}
+	}
+}
+
+// writeError 最近一次写入失败的错误
+type writeError struct {
+	mu  sync.Mutex
+	err error
+	at  time.Time
+
+	reportAt   time.Time // 上次输出到stderr的时间
+	suppressed int       // 上次输出后没有输出的错误数
+}
+
+// LastError 最近一次写入下层writer失败的时间和错误，没有失败过时err为nil
+// 健康检查可以根据时间判断日志是否处于异常状态
+func (w *AsyncRollWriter) LastError() (at time.Time, err error) {
+	w.lastErr.mu.Lock()
+	defer w.lastErr.mu.Unlock()
+	return w.lastErr.at, w.lastErr.err
+}
+
+// isTransient 是否是重试可能恢复的错误，权限不足、已经关闭等错误不重试
+func isTransient(err error) bool {
+	return !errors.Is(err, os.ErrPermission) && !errors.Is(err, ErrClosed) && !errors.Is(err, os.ErrClosed)
+}
+
+// writeRetry 写入下层writer，失败时只重试剩余的部分，只在写日志的协程中调用
+func (w *AsyncRollWriter) writeRetry(p []byte) (int, error) {
+	written := 0
+	backoff := w.opts.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		n, err := w.logger.Write(p[written:])
+		if n > 0 && n <= len(p)-written {
+			written += n
+		}
+		if written >= len(p) && err == nil {
+			return written, nil
+		}
+		if err == nil {
+			err = io.ErrShortWrite
+		}
+
+		if attempt >= w.opts.WriteRetries || !isTransient(err) {
+			w.reportError(err)
+			return written, err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// reportError 记录最近的错误，交给ErrorHandler或者限频输出到stderr
+func (w *AsyncRollWriter) reportError(err error) {
+	now := time.Now()
+	e := &w.lastErr
+	e.mu.Lock()
+	e.err, e.at = err, now
+	report := w.opts.ErrorHandler == nil && now.Sub(e.reportAt) >= errorReportInterval
+	suppressed := e.suppressed
+	if report {
+		e.reportAt, e.suppressed = now, 0
+	} else {
+		e.suppressed++
+	}
+	e.mu.Unlock()
+
+	if w.opts.ErrorHandler != nil {
+		w.opts.ErrorHandler(err)
+		return
+	}
+	if report {
+		fmt.Fprintf(os.Stderr, "rollwriter: async write fail:%v, %d errors suppressed since last report\n", err, suppressed)
+	}
+}
@@ -0,0 +1,93 @@
+package rollwriter
+
+import (
+	"bytes"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// flakyWriter 前fails次写入只写partial字节后返回err
+type flakyWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	fails   int
+	partial int
+	err     error
+	writes  int
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes++
+	if w.writes <= w.fails {
+		n := w.partial
+		if n > len(p) {
+			n = len(p)
+		}
+		w.buf.Write(p[:n])
+		return n, w.err
+	}
+	return w.buf.Write(p)
+}
+
+func TestWriteRetry(t *testing.T) {
+	enospc := &os.PathError{Op: "write", Path: "app.log", Err: syscall.ENOSPC}
+	eacces := &os.PathError{Op: "write", Path: "app.log", Err: syscall.EACCES}
+
+	tests := []struct {
+		name    string
+		retries int
+		fails   int
+		partial int
+		err     error
+		writes  int    // 下层writer被调用的次数
+		content string // 下层writer最终写入的内容
+		failed  bool   // 重试后是否仍然失败
+	}{
+		{"recover", 3, 2, 0, enospc, 3, "hello\n", false},
+		{"partial", 3, 1, 3, enospc, 2, "hello\n", false},
+		{"exhausted", 2, 5, 0, enospc, 3, "", true},
+		{"no retry", -1, 5, 0, enospc, 1, "", true},
+		{"permission", 3, 5, 0, eacces, 1, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fw := &flakyWriter{fails: tt.fails, partial: tt.partial, err: tt.err}
+			var mu sync.Mutex
+			var handled []error
+			w := NewAsyncRollWriter(fw, WithWriteRetry(tt.retries, time.Millisecond), WithErrorHandler(func(err error) {
+				mu.Lock()
+				handled = append(handled, err)
+				mu.Unlock()
+			}))
+
+			start := time.Now()
+			w.Write([]byte("hello\n"))
+			err := w.Close()
+			if (err != nil) != tt.failed {
+				t.Errorf("close err %v, want failed %v", err, tt.failed)
+			}
+			if fw.writes != tt.writes || fw.buf.String() != tt.content {
+				t.Errorf("writes %d content %q, want %d %q", fw.writes, fw.buf.String(), tt.writes, tt.content)
+			}
+
+			at, lastErr := w.LastError()
+			if !tt.failed {
+				if lastErr != nil || len(handled) != 0 {
+					t.Errorf("last error %v handled %v after recovery", lastErr, handled)
+				}
+				return
+			}
+			if lastErr != tt.err || at.Before(start) {
+				t.Errorf("last error %v at %v, want %v", lastErr, at, tt.err)
+			}
+			if len(handled) != 1 || handled[0] != tt.err {
+				t.Errorf("handled %v, want [%v]", handled, tt.err)
+			}
+		})
+	}
+}
@@ -2,8 +2,10 @@ package rollwriter
 
 import (
 	"bytes"
-	"errors"
+	"fmt"
 	"io"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -12,6 +14,19 @@ type AsyncOptions struct {
 	WriteLogSize      int  // 刷盘的大小，单位字节
 	WriterLogInterval int  // 刷盘的间隔时间，单位ms
 	CanDropLog        bool // 是否丢弃日志
+
+	OverflowPolicy  string        // 队列满时的处理策略，见OverflowBlock等
+	OverflowTimeout time.Duration // OverflowBlockTimeout策略的最长阻塞时间
+
+	DropNoticeInterval time.Duration               // 输出丢弃日志提示的最小间隔
+	DropNotice         func(dropped uint64) []byte // 丢弃日志提示的内容
+
+	SpillPath    string // OverflowSpill策略的溢出文件路径，不设置时为下层RollWriter的文件路径加.spill
+	SpillMaxSize int64  // 溢出文件的大小上限，超过后丢弃日志，为0时不限制
+
+	ErrorHandler func(err error) // 写入下层writer失败时的回调
+	WriteRetries int             // 写入下层writer失败后的重试次数
+	RetryBackoff time.Duration   // 第一次重试前的等待时间，之后每次翻倍
 }
 
 type AsyncOption func(*AsyncOptions)
@@ -41,20 +56,44 @@ func WithCanDropLog(drop bool) AsyncOption {
 }
 
 type AsyncRollWriter struct {
+	counters asyncCounters
+
 	logger io.Writer
 	opts   *AsyncOptions
 
-	logChan  chan []byte
-	syncChan chan struct{}
+	logChan  chan *[]byte // 日志缓冲来自logPool，写入批量缓冲后放回
+	syncChan chan flushRequest
+
+	mu     sync.RWMutex  // Write持读锁，Close持写锁，关闭后不会再写入logChan
+	closed bool          // 是否已经关闭
+	done   chan struct{} // 写日志的协程退出
+
+	spill *spillFile // OverflowSpill策略的溢出文件
+
+	lastErr writeError // 最近一次写入失败的错误
+
+	noticed    uint64    // 已经提示过的丢弃条数
+	noticeTime time.Time // 上次提示的时间
+}
+
+// flushRequest Sync和Close的请求，写日志的协程写完队列中的全部日志后返回结果
+type flushRequest struct {
+	result chan error
+	close  bool // 处理完后退出
 }
 
 // 封装一个异步写入的writer
 func NewAsyncRollWriter(logger io.Writer, opt ...AsyncOption) *AsyncRollWriter {
 	// 默认配置
 	opts := &AsyncOptions{
-		LogQueueSize:      1000,
-		WriteLogSize:      2 * 1024,
-		WriterLogInterval: 100,
+		LogQueueSize:       1000,
+		WriteLogSize:       2 * 1024,
+		WriterLogInterval:  100,
+		OverflowTimeout:    defaultOverflowTimeout,
+		DropNoticeInterval: defaultDropNoticeInterval,
+		DropNotice:         defaultDropNotice,
+		WriteRetries:       defaultWriteRetries,
+		RetryBackoff:       defaultRetryBackoff,
 	}
 
 	for _, o := range opt {
@@ -62,69 +101,164 @@ func NewAsyncRollWriter(logger io.Writer, opt ...AsyncOption) *AsyncRollWriter {
 	}
 
 	w := &AsyncRollWriter{}
+	if opts.OverflowPolicy == OverflowSpill {
+		// 打开溢出文件失败时按CanDropLog处理
+		path := spillPath(opts, logger)
+		spill, err := openSpill(path, opts.SpillMaxSize)
+		if err != nil {
+			fmt.Printf("[NewAsyncRollWriter]open spill file %s failed:%+v\n", path, err)
+			opts.OverflowPolicy = ""
+		}
+		w.spill = spill
+	}
+
+	switch opts.OverflowPolicy {
+	case OverflowBlock, OverflowBlockTimeout, OverflowDropNewest, OverflowDropOldest, OverflowLevel, OverflowSpill:
+	default: // 没有设置或者不支持的按CanDropLog
+		opts.OverflowPolicy = OverflowBlock
+		if opts.CanDropLog {
+			opts.OverflowPolicy = OverflowDropNewest
+		}
+	}
+
 	w.logger = logger
 	w.opts = opts
-	w.logChan = make(chan []byte, opts.LogQueueSize)
-	w.syncChan = make(chan struct{})
+	w.logChan = make(chan *[]byte, opts.LogQueueSize)
+	w.syncChan = make(chan flushRequest)
+	w.done = make(chan struct{})
 
 	go w.batchWriteLog()
 
 	return w
 }
 
-// 实现写文件的方法
+// 实现写文件的方法，队列满时按OverflowPolicy处理
 func (w *AsyncRollWriter) Write(data []byte) (int, error) {
-	log := make([]byte, len(data))
-
-	copy(log, data)
-	if w.opts.CanDropLog {
-		select {
-		case w.logChan <- log:
-		default:
-			return 0, errors.New("log is full, drop")
-		}
-	} else {
-		w.logChan <- log
-	}
-
-	return len(data), nil
+	return w.WritePriority(data, PriorityNormal)
 }
 
+// Sync 等待已经写入的日志全部写到下层writer，下层writer支持Sync时一并调用，返回期间的第一个错误
 func (w *AsyncRollWriter) Sync() error {
-	w.syncChan <- struct{}{}
-	return nil
+	w.mu.RLock()
+	if w.closed {
+		w.mu.RUnlock()
+		return nil
+	}
+	req := flushRequest{result: make(chan error, 1)}
+	w.syncChan <- req
+	w.mu.RUnlock()
+
+	return <-req.result
 }
 
+// Close 和Sync一样写完全部日志后停止写日志的协程，并关闭下层writer，之后的写入返回ErrClosed
 func (w *AsyncRollWriter) Close() error {
-	return w.Sync()
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	req := flushRequest{result: make(chan error, 1), close: true}
+	w.syncChan <- req
+	err := <-req.result
+	<-w.done
+
+	if closer, ok := w.logger.(io.Closer); ok {
+		if e := closer.Close(); err == nil {
+			err = e
+		}
+	}
+	return err
 }
 
 func (w *AsyncRollWriter) batchWriteLog() {
+	defer close(w.done)
+
 	buffer := bytes.NewBuffer(make([]byte, 0, w.opts.WriteLogSize*2)) // 用来管理缓冲区，用于管理待写入的数据
 
 	ticker := time.NewTicker(time.Millisecond * time.Duration(w.opts.WriterLogInterval)) // 用于定期刷新到日志的定时器
+	defer ticker.Stop()
+
+	var writeErr error // 上次Sync后第一次写入失败的错误
+	flush := func() error {
+		if buffer.Len() == 0 {
+			return nil
+		}
+		n, err := w.writeRetry(buffer.Bytes())
+		if err != nil && writeErr == nil {
+			writeErr = err
+		}
+		atomic.AddUint64(&w.counters.bytesWritten, uint64(n))
+		atomic.AddUint64(&w.counters.flushes, 1)
+		buffer.Reset()
+		return err
+	}
 
 	for {
 		select {
 		case <-ticker.C: // 到了定期刷新的时间，则刷新一下
-			if buffer.Len() > 0 {
-				_, _ = w.logger.Write(buffer.Bytes())
-				buffer.Reset()
+			w.noticeDropped(buffer, false)
+			flush()
+			if w.spill != nil {
+				w.spill.tryAcquire()
+				w.replaySpill(buffer, flush, w.spill.end())
 			}
 		case data := <-w.logChan: // 将日志从ch转移到buffer中
-			buffer.Write(data)
+			buffer.Write(*data)
+			putLog(data)
+			// 队列中已有的日志一起取出，减少select的次数
+			for more := true; more && buffer.Len() < w.opts.WriteLogSize; {
+				select {
+				case data = <-w.logChan:
+					buffer.Write(*data)
+					putLog(data)
+				default:
+					more = false
+				}
+			}
 			if buffer.Len() >= w.opts.WriteLogSize { // 如果超过了最大大小，则直接刷新
-				_, _ = w.logger.Write(buffer.Bytes())
-				buffer.Reset()
+				flush()
 			}
-		case <-w.syncChan:
-			if buffer.Len() > 0 {
-				_, _ = w.logger.Write(buffer.Bytes())
-				buffer.Reset()
+		case req := <-w.syncChan:
+			// 队列中的日志也全部写入
+			for drained := false; !drained; {
+				select {
+				case data := <-w.logChan:
+					buffer.Write(*data)
+					putLog(data)
+					if buffer.Len() >= w.opts.WriteLogSize {
+						flush()
+					}
+				default:
+					drained = true
+				}
 			}
+			if w.spill != nil {
+				w.replaySpill(buffer, flush, w.spill.end())
+			}
+			w.noticeDropped(buffer, req.close)
+			flush()
 
-			// todo
-			// 是否需要强制刷新ch中的日志
+			err := writeErr
+			writeErr = nil
+			if syncer, ok := w.logger.(Syncer); ok {
+				if e := syncer.Sync(); err == nil {
+					err = e
+				}
+			}
+			if req.close && w.spill != nil {
+				if e := w.spill.close(); err == nil {
+					err = e
+				}
+			}
+			req.result <- err
+
+			if req.close {
+				return
+			}
 		}
 	}
 }
@@ -0,0 +1,120 @@
+package rollwriter
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+// legacyAsyncWriter 改用logPool之前的实现，每次Write分配新的切片复制日志，用于对比
+type legacyAsyncWriter struct {
+	logger  io.Writer
+	logChan chan []byte
+	done    chan struct{}
+}
+
+func newLegacyAsyncWriter(logger io.Writer) *legacyAsyncWriter {
+	w := &legacyAsyncWriter{
+		logger:  logger,
+		logChan: make(chan []byte, 1000),
+		done:    make(chan struct{}),
+	}
+	go w.batchWriteLog()
+	return w
+}
+
+func (w *legacyAsyncWriter) Write(data []byte) (int, error) {
+	log := make([]byte, len(data))
+	copy(log, data)
+	w.logChan <- log
+	return len(data), nil
+}
+
+func (w *legacyAsyncWriter) Close() {
+	close(w.logChan)
+	<-w.done
+}
+
+func (w *legacyAsyncWriter) batchWriteLog() {
+	defer close(w.done)
+
+	buffer := bytes.NewBuffer(make([]byte, 0, 4*1024))
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_, _ = w.logger.Write(buffer.Bytes())
+			buffer.Reset()
+		case data, ok := <-w.logChan:
+			if !ok {
+				_, _ = w.logger.Write(buffer.Bytes())
+				return
+			}
+			buffer.Write(data)
+			if buffer.Len() >= 2*1024 {
+				_, _ = w.logger.Write(buffer.Bytes())
+				buffer.Reset()
+			}
+		}
+	}
+}
+
+var benchLog = []byte(strings.Repeat("x", 199) + "\n")
+
+func BenchmarkAsyncRollWriter_Write(b *testing.B) {
+	w := NewAsyncRollWriter(ioutil.Discard)
+	defer w.Close()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchLog)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = w.Write(benchLog)
+	}
+	_ = w.Sync()
+}
+
+func BenchmarkLegacyAsyncWriter_Write(b *testing.B) {
+	w := newLegacyAsyncWriter(ioutil.Discard)
+	defer w.Close()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchLog)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = w.Write(benchLog)
+	}
+}
+
+func BenchmarkAsyncRollWriter_WriteParallel(b *testing.B) {
+	w := NewAsyncRollWriter(ioutil.Discard)
+	defer w.Close()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchLog)))
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = w.Write(benchLog)
+		}
+	})
+	_ = w.Sync()
+}
+
+func BenchmarkLegacyAsyncWriter_WriteParallel(b *testing.B) {
+	w := newLegacyAsyncWriter(ioutil.Discard)
+	defer w.Close()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchLog)))
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = w.Write(benchLog)
+		}
+	})
+}
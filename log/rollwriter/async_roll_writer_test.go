@@ -0,0 +1,248 @@
+package rollwriter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// testWriter 记录写入内容的下层writer，可以阻塞第一次写入和模拟写入失败
+type testWriter struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	err    error // 不为nil时写入失败
+	writes int
+
+	once    sync.Once
+	gate    chan struct{} // 不为nil时第一次写入阻塞到关闭
+	entered chan struct{} // 第一次写入开始时关闭
+}
+
+func newTestWriter(block bool) *testWriter {
+	w := &testWriter{entered: make(chan struct{})}
+	if block {
+		w.gate = make(chan struct{})
+	}
+	return w
+}
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() {
+		close(w.entered)
+		if w.gate != nil {
+			<-w.gate
+		}
+	})
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes++
+	if w.err != nil {
+		return 0, w.err
+	}
+	return w.buf.Write(p)
+}
+
+func (w *testWriter) setErr(err error) {
+	w.mu.Lock()
+	w.err = err
+	w.mu.Unlock()
+}
+
+func (w *testWriter) lines() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	s := strings.TrimSuffix(w.buf.String(), "\n")
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, "\n")
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "rollwriter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func removeAll(dir string) {
+	_ = os.RemoveAll(dir)
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err       error
+		transient bool
+	}{
+		{&os.PathError{Op: "write", Path: "app.log", Err: syscall.ENOSPC}, true},
+		{&os.PathError{Op: "open", Path: "app.log", Err: syscall.EACCES}, false},
+		{fmt.Errorf("reopen: %w", &os.PathError{Op: "open", Path: "app.log", Err: syscall.EPERM}), false},
+		{ErrClosed, false},
+		{os.ErrClosed, false},
+	}
+	for _, tt := range tests {
+		if got := isTransient(tt.err); got != tt.transient {
+			t.Errorf("isTransient(%v) = %v, want %v", tt.err, got, tt.transient)
+		}
+	}
+}
+
+// syncWriter 支持Sync和Close的下层writer
+type syncWriter struct {
+	*testWriter
+	syncs  int32
+	closes int32
+}
+
+func (w *syncWriter) Sync() error {
+	atomic.AddInt32(&w.syncs, 1)
+	return nil
+}
+
+func (w *syncWriter) Close() error {
+	atomic.AddInt32(&w.closes, 1)
+	return nil
+}
+
+func TestAsyncSync(t *testing.T) {
+	under := &syncWriter{testWriter: newTestWriter(false)}
+	// 刷盘间隔和大小都很大，只有Sync和Close会写入
+	w := NewAsyncRollWriter(under, WithWriteLogInterval(60000), WithWriteLogSize(1<<20), WithWriteRetry(-1, 0))
+
+	for i := 0; i < 100; i++ {
+		if _, err := w.Write([]byte(fmt.Sprintf("%d\n", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Sync(); err != nil {
+		t.Fatalf("sync fail:%v", err)
+	}
+	if got := under.lines(); !reflect.DeepEqual(got, seq(0, 100)) {
+		t.Errorf("lines after sync %v", got)
+	}
+	if n := atomic.LoadInt32(&under.syncs); n != 1 {
+		t.Errorf("underlying synced %d times, want 1", n)
+	}
+
+	// 写入失败的错误由下一次Sync返回一次
+	under.setErr(errors.New("disk error"))
+	w.Write([]byte("fail\n"))
+	if err := w.Sync(); err == nil {
+		t.Error("sync should return the write error")
+	}
+	under.setErr(nil)
+	if err := w.Sync(); err != nil {
+		t.Errorf("sync after recovery err %v", err)
+	}
+
+	w.Write([]byte("last\n"))
+	if err := w.Close(); err != nil {
+		t.Fatalf("close fail:%v", err)
+	}
+	if got := under.lines(); got[len(got)-1] != "last" {
+		t.Errorf("last line after close %q, want last", got[len(got)-1])
+	}
+	if _, err := w.Write([]byte("closed\n")); err != ErrClosed {
+		t.Errorf("write after close err %v, want ErrClosed", err)
+	}
+	if err := w.Sync(); err != nil {
+		t.Errorf("sync after close err %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("close twice err %v", err)
+	}
+	if n := atomic.LoadInt32(&under.closes); n != 1 {
+		t.Errorf("underlying closed %d times, want 1", n)
+	}
+}
+
+func TestAsyncSyncConcurrent(t *testing.T) {
+	under := newTestWriter(false)
+	w := NewAsyncRollWriter(under, WithLogQueueSize(10), WithWriteLogInterval(60000))
+	defer w.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				w.Write([]byte("x\n"))
+			}
+			if err := w.Sync(); err != nil {
+				t.Errorf("sync fail:%v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := len(under.lines()); n != 400 {
+		t.Errorf("got %d lines after sync, want 400", n)
+	}
+}
+
+func TestAsyncStats(t *testing.T) {
+	tw := newTestWriter(false)
+	w := NewAsyncRollWriter(tw)
+	defer w.Close()
+
+	for i := 0; i < 10; i++ {
+		w.Write([]byte("x\n"))
+	}
+	if err := w.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	st := w.Stats()
+	if st.Accepted != 10 || st.Dropped != 0 || st.BytesWritten != 20 || st.Flushes == 0 || st.Spilled != 0 {
+		t.Errorf("stats %+v", st)
+	}
+}
+
+func TestDropNotice(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		notices  []string
+	}{
+		{"every flush", 0, []string{"dropped 4"}},
+		{"forced on close", time.Hour, []string{"dropped 4"}},
+		{"disabled", -1, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, tw := newBlockedWriter(t, WithLogQueueSize(1), WithCanDropLog(true),
+				WithDropNoticeInterval(tt.interval),
+				WithDropNotice(func(dropped uint64) []byte { return []byte(fmt.Sprintf("dropped %d\n", dropped)) }))
+			for i := 1; i <= 5; i++ {
+				fmt.Fprintln(w, i)
+			}
+			close(tw.gate)
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			notices := make([]string, 0)
+			for _, line := range tw.lines() {
+				if strings.HasPrefix(line, "dropped") {
+					notices = append(notices, line)
+				}
+			}
+			if !reflect.DeepEqual(notices, tt.notices) {
+				t.Errorf("notices %v, want %v", notices, tt.notices)
+			}
+			if st := w.Stats(); st.Accepted != 2 || st.Dropped != 4 {
+				t.Errorf("stats %+v", st)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package rollwriter
+
+import (
+	"bytes"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// defaultDropNoticeInterval 默认输出丢弃日志提示的最小间隔
+const defaultDropNoticeInterval = 10 * time.Second
+
+// AsyncStats 异步写入的统计
+type AsyncStats struct {
+	Accepted     uint64 // 放入队列的日志条数
+	Dropped      uint64 // 队列满时丢弃的日志条数，包括OverflowDropOldest丢弃的队列中的日志
+	BytesWritten uint64 // 写入下层writer的字节数
+	Flushes      uint64 // 写入下层writer的次数
+	Spilled      uint64 // 写入溢出文件的日志条数
+}
+
+// asyncCounters 异步写入的计数器，放在结构体开头保证32位系统上64位原子操作对齐
+type asyncCounters struct {
+	accepted     uint64
+	dropped      uint64
+	bytesWritten uint64
+	flushes      uint64
+	spilled      uint64
+}
+
+// WithDropNoticeInterval 设置输出丢弃日志提示的最小间隔，小于0时不输出
+func WithDropNoticeInterval(d time.Duration) AsyncOption {
+	return func(opts *AsyncOptions) {
+		opts.DropNoticeInterval = d
+	}
+}
+
+// WithDropNotice 设置丢弃日志提示的内容，参数为上次提示后丢弃的条数，返回写入日志文件的一行
+func WithDropNotice(f func(dropped uint64) []byte) AsyncOption {
+	return func(opts *AsyncOptions) {
+		opts.DropNotice = f
+	}
+}
+
+// defaultDropNotice 默认的丢弃日志提示
+func defaultDropNotice(dropped uint64) []byte {
+	return []byte(fmt.Sprintf("%s\tWARN\trollwriter: %d log entries dropped since last notice\n",
+		time.Now().Format("2006-01-02 15:04:05.000"), dropped))
+}
+
+// Stats 获取异步写入的统计
+func (w *AsyncRollWriter) Stats() AsyncStats {
+	return AsyncStats{
+		Accepted:     atomic.LoadUint64(&w.counters.accepted),
+		Dropped:      atomic.LoadUint64(&w.counters.dropped),
+		BytesWritten: atomic.LoadUint64(&w.counters.bytesWritten),
+		Flushes:      atomic.LoadUint64(&w.counters.flushes),
+		Spilled:      atomic.LoadUint64(&w.counters.spilled),
+	}
+}
+
+// noticeDropped 距离上次提示超过间隔或者force时，把上次提示后丢弃的条数作为一行日志写入buffer，只在写日志的协程中调用
+func (w *AsyncRollWriter) noticeDropped(buffer *bytes.Buffer, force bool) {
+	if w.opts.DropNoticeInterval < 0 {
+		return
+	}
+
+	now := time.Now()
+	if !force && now.Sub(w.noticeTime) < w.opts.DropNoticeInterval {
+		return
+	}
+
+	dropped := atomic.LoadUint64(&w.counters.dropped)
+	if dropped == w.noticed {
+		return
+	}
+	buffer.Write(w.opts.DropNotice(dropped - w.noticed))
+	w.noticed = dropped
+	w.noticeTime = now
+}
@@ -0,0 +1,146 @@
+package rollwriter
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	compressSuffix    = ".gz"
+	compressTmpSuffix = ".gz.tmp"
+)
+
+// compressHistory 压缩所有还没有压缩、句柄已经关闭的历史文件，只在清理协程中调用
+func (w *RollWriter) compressHistory() {
+	w.removeCompressTmp()
+
+	files, err := w.getDirHistory()
+	if err != nil {
+		return
+	}
+
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), compressSuffix) {
+			continue
+		}
+		path := filepath.Join(f.dir, f.Name())
+		w.histMu.Lock()
+		if w.isPending(path) { // 还可能有写入，等句柄关闭后再压缩
+			w.histMu.Unlock()
+			continue
+		}
+		err := compressFile(path)
+		w.histMu.Unlock()
+		if err != nil && !os.IsNotExist(err) { // 已经被归档或者删除
+			fmt.Fprintf(os.Stderr, "rollwriter: compress %s fail:%v\n", f.Name(), err)
+		}
+	}
+}
+
+// addPending 记录滚动出的文件，句柄关闭前不压缩
+func (w *RollWriter) addPending(st os.FileInfo) {
+	if st == nil {
+		return
+	}
+	w.histMu.Lock()
+	w.pending = append(w.pending, st)
+	w.histMu.Unlock()
+}
+
+// removePending 文件句柄已经关闭，可以压缩
+func (w *RollWriter) removePending(st os.FileInfo) {
+	if st == nil {
+		return
+	}
+	w.histMu.Lock()
+	for i, p := range w.pending {
+		if os.SameFile(p, st) {
+			w.pending = append(w.pending[:i], w.pending[i+1:]...)
+			break
+		}
+	}
+	w.histMu.Unlock()
+}
+
+// isPending 文件句柄是否还没有关闭，按设备号和inode比较，不受改名影响，调用方持有histMu
+func (w *RollWriter) isPending(path string) bool {
+	if len(w.pending) == 0 {
+		return false
+	}
+	st, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	for _, p := range w.pending {
+		if os.SameFile(p, st) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeCompressTmp 删除当前目录和归档目录下上次压缩中断留下的临时文件，
+// 压缩只在清理协程中进行，此时不会有正在写的临时文件
+func (w *RollWriter) removeCompressTmp() {
+	dirs := append([]string{w.currDir}, w.archiveDirs()...)
+	for _, dir := range dirs {
+		pattern := filepath.Join(dir, filepath.Base(w.filePath)+"*"+compressTmpSuffix)
+		tmps, _ := filepath.Glob(pattern)
+		for _, tmp := range tmps {
+			os.Remove(tmp)
+		}
+	}
+}
+
+// compressFile gzip压缩文件，先写临时文件再重命名，进程中途退出时不会留下不完整的.gz文件
+// 压缩后的文件保留原文件的修改时间，保证按时间清理的顺序不变
+func compressFile(src string) error {
+	dst := src + compressSuffix
+	if _, err := os.Stat(dst); err == nil { // 上次已经压缩完成，只是没有删除原文件
+		return os.Remove(src)
+	}
+
+	st, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := src + compressTmpSuffix
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, st.Mode())
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(out)
+	_, err = io.Copy(gz, in)
+	if err == nil {
+		err = gz.Close()
+	}
+	if err == nil {
+		err = out.Sync()
+	}
+	if e := out.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	_ = os.Chtimes(tmp, st.ModTime(), st.ModTime())
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Remove(src)
+}
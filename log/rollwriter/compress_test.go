@@ -0,0 +1,128 @@
+package rollwriter
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// withMaxBytes 以字节为单位设置文件大小上限
+func withMaxBytes(n int64) Option {
+	return func(opt *Options) {
+		opt.MaxSize = n
+	}
+}
+
+func readGzip(t *testing.T, path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("open gzip %s fail:%v", path, err)
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read gzip %s fail:%v", path, err)
+	}
+	return string(data)
+}
+
+func TestCompressFile(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+
+	src := filepath.Join(dir, "app.log.1")
+	if err := ioutil.WriteFile(src, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	_ = os.Chtimes(src, mtime, mtime)
+
+	if err := compressFile(src); err != nil {
+		t.Fatalf("compress fail:%v", err)
+	}
+	if fileExist(src) || fileExist(src+compressTmpSuffix) {
+		t.Error("source or tmp file left")
+	}
+	if got := readGzip(t, src+compressSuffix); got != "hello" {
+		t.Errorf("gzip content %q", got)
+	}
+	if st, err := os.Stat(src + compressSuffix); err != nil || !st.ModTime().Equal(mtime) {
+		t.Errorf("gzip mtime %v, want %v", st.ModTime(), mtime)
+	}
+
+	// 上次已经压缩完成但没有删除原文件
+	if err := ioutil.WriteFile(src, []byte("again"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := compressFile(src); err != nil || fileExist(src) {
+		t.Errorf("compress with existing gzip err %v, source exist %v", err, fileExist(src))
+	}
+	if got := readGzip(t, src+compressSuffix); got != "hello" {
+		t.Errorf("existing gzip overwritten, content %q", got)
+	}
+}
+
+func TestCompressSkipPending(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+
+	w, err := NewRollWriter(filepath.Join(dir, "app.log"), WithCompress(true), WithBackupNaming(BackupNameAscending))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	backup := filepath.Join(dir, "app.log.1")
+	if err := ioutil.WriteFile(backup, []byte("rotated"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	st, err := os.Stat(backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 句柄还没有关闭时不压缩
+	w.addPending(st)
+	w.compressHistory()
+	if !fileExist(backup) || fileExist(backup+compressSuffix) {
+		t.Fatal("file with open handle compressed")
+	}
+
+	w.removePending(st)
+	w.compressHistory()
+	if fileExist(backup) || readGzip(t, backup+compressSuffix) != "rotated" {
+		t.Error("file not compressed after handle closed")
+	}
+}
+
+func TestCompressRotated(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+
+	w, err := NewRollWriter(filepath.Join(dir, "app.log"), withMaxBytes(5), WithCompress(true),
+		WithBackupNaming(BackupNameAscending))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"first", "second", "third"} {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{"app.log.1.gz": "first", "app.log.2.gz": "second", "app.log.3.gz": "third"} {
+		if got := readGzip(t, filepath.Join(dir, name)); got != want {
+			t.Errorf("%s content %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package rollwriter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// currentLinkTmpSuffix 创建软链接时使用的临时文件后缀，创建后rename覆盖原链接
+const currentLinkTmpSuffix = ".link.tmp"
+
+// WithCurrentLink 按时间滚动时在filePath维护一个指向当前文件的软链接，方便tail -F
+func WithCurrentLink(b bool) Option {
+	return func(opt *Options) {
+		opt.CurrentLink = b
+	}
+}
+
+// updateCurrentLink 把filePath的软链接指向当前文件，先创建临时链接再rename，保证替换是原子的
+// filePath已经是普通文件时不覆盖
+func (w *RollWriter) updateCurrentLink() {
+	if !w.opts.CurrentLink || w.currPath == w.filePath {
+		return
+	}
+
+	target := filepath.Base(w.currPath)
+	if st, err := os.Lstat(w.filePath); err == nil {
+		if st.Mode()&os.ModeSymlink == 0 {
+			w.linkWarn.Do(func() {
+				fmt.Fprintf(os.Stderr, "rollwriter: %s is not a symlink, skip current link\n", w.filePath)
+			})
+			return
+		}
+		if dst, _ := os.Readlink(w.filePath); dst == target { // 已经指向当前文件
+			return
+		}
+	}
+
+	tmp := w.filePath + currentLinkTmpSuffix
+	_ = os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, w.filePath); err != nil {
+		_ = os.Remove(tmp)
+	}
+}
+
+// isCurrentLink 是否是当前文件的软链接或者其临时文件，不作为历史文件
+func (w *RollWriter) isCurrentLink(name string) bool {
+	if !w.opts.CurrentLink {
+		return false
+	}
+	base := filepath.Base(w.filePath)
+	return name == base || name == base+currentLinkTmpSuffix
+}
@@ -0,0 +1,83 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package rollwriter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCurrentLink(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+
+	// 上次进程退出前指向旧文件的链接
+	path := filepath.Join(dir, "app.log")
+	if err := ioutil.WriteFile(path+".20000101", []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("app.log.20000101", path); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewRollWriter(path, WithTimeFormat(".%Y%m%d"), WithCurrentLink(true), WithMaxHistory(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	want := "app.log" + time.Now().Format(".20060102")
+	if _, err := w.Write([]byte("new")); err != nil {
+		t.Fatal(err)
+	}
+
+	if dst, err := os.Readlink(path); err != nil || dst != want {
+		t.Errorf("link points to %q err %v, want %s", dst, err, want)
+	}
+	if got := readFile(t, path); got != "new" {
+		t.Errorf("content through link %q, want new", got)
+	}
+	if fileExist(path + currentLinkTmpSuffix) {
+		t.Error("tmp link left")
+	}
+
+	// 链接不作为历史文件
+	files, err := w.getDirHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if f.Name() == "app.log" {
+			t.Error("current link listed as history")
+		}
+	}
+}
+
+func TestCurrentLinkRegularFile(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+
+	// 已经是普通文件时不覆盖
+	path := filepath.Join(dir, "app.log")
+	if err := ioutil.WriteFile(path, []byte("regular"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewRollWriter(path, WithTimeFormat(".%Y%m%d"), WithCurrentLink(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if _, err := w.Write([]byte("new")); err != nil {
+		t.Fatal(err)
+	}
+
+	if st, err := os.Lstat(path); err != nil || st.Mode()&os.ModeSymlink != 0 {
+		t.Fatalf("regular file replaced, err %v", err)
+	}
+	if got := readFile(t, path); got != "regular" {
+		t.Errorf("regular file content %q", got)
+	}
+}
@@ -0,0 +1,97 @@
+package rollwriter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// defaultDiskCheckInterval 默认磁盘剩余空间检查间隔
+const defaultDiskCheckInterval = 10 * time.Second
+
+// WithMinFreeSpace 设置日志所在磁盘的最小剩余空间，单位MB，低于该值时清理历史文件，仍然不足时进入降级模式
+func WithMinFreeSpace(size int64) Option {
+	return func(opt *Options) {
+		opt.MinFreeSpace = size * 1024 * 1024
+	}
+}
+
+// WithDiskCheckInterval 设置磁盘剩余空间检查间隔
+func WithDiskCheckInterval(d time.Duration) Option {
+	return func(opt *Options) {
+		opt.DiskCheckInterval = d
+	}
+}
+
+// LowSpace 磁盘剩余空间是否不足，不足时上层应只写入重要的日志
+// 降级期间低级别日志不会调用Write，在这里按检查间隔重新检查，空间恢复后退出降级模式
+func (w *RollWriter) LowSpace() bool {
+	if atomic.LoadInt32(&w.lowSpace) == 0 {
+		return false
+	}
+	w.checkDiskSpace()
+	return atomic.LoadInt32(&w.lowSpace) == 1
+}
+
+// checkDiskSpace 定期检查剩余空间，不足时通知清理协程删除历史文件
+func (w *RollWriter) checkDiskSpace() {
+	now := time.Now().UnixNano()
+	last := atomic.LoadInt64(&w.diskCheckTime)
+	if now-last < int64(w.opts.DiskCheckInterval) || !atomic.CompareAndSwapInt64(&w.diskCheckTime, last, now) {
+		return
+	}
+
+	free, err := diskFree(w.currDir)
+	if err != nil { // 不支持或者取不到时不做保护
+		return
+	}
+	if free >= uint64(w.opts.MinFreeSpace) {
+		w.setLowSpace(false, free)
+		return
+	}
+
+	atomic.StoreInt32(&w.needFreeSpace, 1)
+	w.mu.Lock()
+	if !w.isClosed() {
+		w.notifyClose()
+	}
+	w.mu.Unlock()
+}
+
+// freeDiskSpace 从最旧的历史文件开始删除，直到剩余空间足够或者没有历史文件，只在清理协程中调用
+func (w *RollWriter) freeDiskSpace() {
+	free, err := diskFree(w.currDir)
+	if err != nil {
+		return
+	}
+
+	files, _ := w.getDirHistory()
+	for i := len(files) - 1; i >= 0 && free < uint64(w.opts.MinFreeSpace); i-- {
+		w.histMu.Lock()
+		w.removeFile(files[i : i+1])
+		w.histMu.Unlock()
+
+		if free, err = diskFree(w.currDir); err != nil {
+			return
+		}
+	}
+
+	w.setLowSpace(free < uint64(w.opts.MinFreeSpace), free)
+}
+
+// setLowSpace 状态变化时输出一次提示到stderr
+func (w *RollWriter) setLowSpace(low bool, free uint64) {
+	if low {
+		if atomic.CompareAndSwapInt32(&w.lowSpace, 0, 1) {
+			fmt.Fprintf(os.Stderr, "rollwriter: free space of %s is %dMB, below %dMB, drop low level logs\n",
+				filepath.Dir(w.filePath), free>>20, w.opts.MinFreeSpace>>20)
+		}
+		return
+	}
+
+	if atomic.CompareAndSwapInt32(&w.lowSpace, 1, 0) {
+		fmt.Fprintf(os.Stderr, "rollwriter: free space of %s recovered to %dMB\n", filepath.Dir(w.filePath), free>>20)
+	}
+}
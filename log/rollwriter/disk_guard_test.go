@@ -0,0 +1,74 @@
+package rollwriter
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLowSpace(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	if _, err := diskFree(dir); err != nil {
+		t.Skipf("disk free space not supported:%v", err)
+	}
+
+	for _, name := range []string{"app.log.1", "app.log.2"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 剩余空间不可能满足，删除历史文件后进入降级模式
+	w, err := NewRollWriter(filepath.Join(dir, "app.log"), WithBackupNaming(BackupNameAscending),
+		func(opt *Options) { opt.MinFreeSpace = 1 << 62 }, WithDiskCheckInterval(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if w.LowSpace() {
+		t.Fatal("low space before check")
+	}
+	if _, err := w.Write([]byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !w.LowSpace() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !w.LowSpace() {
+		t.Fatal("not in low space mode")
+	}
+	for _, name := range []string{"app.log.1", "app.log.2"} {
+		if fileExist(filepath.Join(dir, name)) {
+			t.Errorf("history file %s not removed", name)
+		}
+	}
+	if !fileExist(filepath.Join(dir, "app.log")) {
+		t.Error("current file removed")
+	}
+
+}
+
+func TestLowSpaceRecover(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	if _, err := diskFree(dir); err != nil {
+		t.Skipf("disk free space not supported:%v", err)
+	}
+
+	w, err := NewRollWriter(filepath.Join(dir, "app.log"), WithMinFreeSpace(1), WithDiskCheckInterval(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// 降级期间没有写入，LowSpace重新检查，空间足够时退出降级模式
+	atomic.StoreInt32(&w.lowSpace, 1)
+	if w.LowSpace() {
+		t.Error("still in low space mode after recovery")
+	}
+}
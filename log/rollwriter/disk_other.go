@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd
+// +build !linux,!darwin,!freebsd
+
+package rollwriter
+
+import (
+	"errors"
+)
+
+// diskFree 其他系统暂不支持检查剩余空间
+func diskFree(dir string) (uint64, error) {
+	return 0, errors.New("disk free space not supported")
+}
@@ -0,0 +1,31 @@
+package rollwriter
+
+import (
+	"sync"
+)
+
+// maxPooledLogSize 超过该大小的日志缓冲不放回池中，避免偶尔的大日志长期占用内存
+const maxPooledLogSize = 64 * 1024
+
+// logPool 异步写入时每条日志的缓冲，写入批量缓冲后放回，避免每次Write都分配内存
+var logPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 512)
+		return &b
+	},
+}
+
+// getLog 从池中取缓冲并复制日志
+func getLog(data []byte) *[]byte {
+	b := logPool.Get().(*[]byte)
+	*b = append((*b)[:0], data...)
+	return b
+}
+
+// putLog 放回池中
+func putLog(b *[]byte) {
+	if cap(*b) > maxPooledLogSize {
+		return
+	}
+	logPool.Put(b)
+}
@@ -0,0 +1,140 @@
+package rollwriter
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/lestrrat-go/strftime"
+)
+
+// 超过大小的备份文件命名方式
+const (
+	// BackupNameTimestamp 按时间戳命名 app.log.bk-20261016-120000，同一秒内重复时再加编号
+	BackupNameTimestamp = "timestamp"
+	// BackupNameShift 编号后移，和logrotate一致，app.log.1为最新，之前的.1改名为.2，依次类推
+	BackupNameShift = "shift"
+	// BackupNameAscending 编号递增，app.log.1为最旧，新的备份编号为已有最大编号加1
+	BackupNameAscending = "ascending"
+)
+
+// defaultBackupTimeFormat 默认备份文件时间戳格式
+const defaultBackupTimeFormat = "bk-%Y%m%d-%H%M%S"
+
+// initBackupNaming 初始化备份文件命名方式和匹配历史文件的正则
+func (w *RollWriter) initBackupNaming() error {
+	opts := w.opts
+	if opts.BackupNaming == "" {
+		opts.BackupNaming = BackupNameTimestamp
+		if opts.RollType == RollByTimeAndSize {
+			opts.BackupNaming = BackupNameAscending
+		}
+	}
+
+	var backupRe string
+	switch opts.BackupNaming {
+	case BackupNameTimestamp:
+		if opts.BackupTimeFormat == "" {
+			opts.BackupTimeFormat = defaultBackupTimeFormat
+		}
+		backup, err := strftime.New(opts.BackupTimeFormat)
+		if err != nil {
+			return err
+		}
+		w.backup = backup
+		backupRe = strftimeRegexp(opts.BackupTimeFormat) + `(?:\.\d+)?`
+	case BackupNameShift, BackupNameAscending:
+		backupRe = `\d+`
+	default:
+		return fmt.Errorf("backup naming %s invalid", opts.BackupNaming)
+	}
+
+	history, err := regexp.Compile("^(" + regexp.QuoteMeta(filepath.Base(w.filePath)) +
+		strftimeRegexp(opts.TimeFormat) + `)(?:\.(` + backupRe + `))?(?:` + regexp.QuoteMeta(compressSuffix) + `)?$`)
+	if err != nil {
+		return err
+	}
+	w.history = history
+	return nil
+}
+
+// backupName 当前文件超过大小时的备份文件名，编号后移方式会先把已有的备份文件编号加1，调用方持有histMu
+func (w *RollWriter) backupName() string {
+	switch w.opts.BackupNaming {
+	case BackupNameShift:
+		w.shiftBackups()
+		return w.currPath + ".1"
+	case BackupNameAscending:
+		indexes := w.backupIndexes()
+		next := 1
+		if len(indexes) > 0 {
+			next = indexes[len(indexes)-1] + 1
+		}
+		return w.currPath + "." + strconv.Itoa(next)
+	default:
+		name := w.currPath + "." + w.backup.FormatString(time.Now())
+		for i := 1; fileExist(name) || fileExist(name+compressSuffix); i++ {
+			name = w.currPath + "." + w.backup.FormatString(time.Now()) + "." + strconv.Itoa(i)
+		}
+		return name
+	}
+}
+
+// shiftBackups 从最大编号开始依次把 .N 改名为 .N+1，已压缩的文件保留.gz后缀
+func (w *RollWriter) shiftBackups() {
+	indexes := w.backupIndexes()
+	for i := len(indexes) - 1; i >= 0; i-- {
+		from := w.currPath + "." + strconv.Itoa(indexes[i])
+		to := w.currPath + "." + strconv.Itoa(indexes[i]+1)
+		if fileExist(from + compressSuffix) {
+			from, to = from+compressSuffix, to+compressSuffix
+		}
+		_ = os.Rename(from, to)
+	}
+}
+
+// backupIndexes 当前文件已有的数字编号备份，从小到大排序
+func (w *RollWriter) backupIndexes() []int {
+	files, _ := ioutil.ReadDir(w.currDir)
+
+	indexes := make([]int, 0)
+	seen := make(map[int]bool)
+	currGroup := filepath.Base(w.currPath)
+	for _, f := range files {
+		m := w.history.FindStringSubmatch(f.Name())
+		if m == nil || m[1] != currGroup || m[2] == "" {
+			continue
+		}
+		if n, err := strconv.Atoi(m[2]); err == nil && !seen[n] {
+			seen[n] = true
+			indexes = append(indexes, n)
+		}
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// backupOrder 按命名方式给出的备份新旧顺序，越大越新
+func (w *RollWriter) backupOrder(suffix string) int {
+	n, err := strconv.Atoi(suffix)
+	if err != nil {
+		return 0
+	}
+	switch w.opts.BackupNaming {
+	case BackupNameShift:
+		return -n
+	case BackupNameAscending:
+		return n
+	}
+	return 0
+}
+
+func fileExist(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
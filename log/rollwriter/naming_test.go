@@ -0,0 +1,130 @@
+package rollwriter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newNamingWriter 在临时目录中创建文件后创建RollWriter并打开当前文件
+func newNamingWriter(t *testing.T, naming string, files map[string]string) (*RollWriter, string) {
+	dir, err := ioutil.TempDir("", "rollwriter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w, err := NewRollWriter(filepath.Join(dir, "app.log"), WithMaxSize(1), WithBackupNaming(naming))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("curr")); err != nil { // 第一次写入时打开当前文件
+		t.Fatal(err)
+	}
+	return w, dir
+}
+
+// checkFiles 检查文件内容，content为空时文件应该不存在
+func checkFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if content == "" {
+			if err == nil {
+				t.Errorf("%s should not exist", name)
+			}
+			continue
+		}
+		if err != nil || string(data) != content {
+			t.Errorf("%s content %q err %v, want %q", name, data, err, content)
+		}
+	}
+}
+
+func TestBackupNameShift(t *testing.T) {
+	w, dir := newNamingWriter(t, BackupNameShift, map[string]string{
+		"app.log.1":    "one",
+		"app.log.2.gz": "two",
+		"app.log.3":    "three",
+		"other.log.1":  "other",
+	})
+	defer os.RemoveAll(dir)
+	defer w.Close()
+
+	w.histMu.Lock()
+	name := w.backupName()
+	w.histMu.Unlock()
+
+	if name != filepath.Join(dir, "app.log.1") {
+		t.Errorf("backup name %s, want app.log.1", name)
+	}
+	// 已有的备份编号都加1，压缩的文件保持压缩后缀
+	checkFiles(t, dir, map[string]string{
+		"app.log.1":    "",
+		"app.log.2":    "one",
+		"app.log.3.gz": "two",
+		"app.log.4":    "three",
+		"other.log.1":  "other",
+	})
+}
+
+func TestBackupNameAscending(t *testing.T) {
+	w, dir := newNamingWriter(t, BackupNameAscending, map[string]string{
+		"app.log.1":    "one",
+		"app.log.3.gz": "three",
+		"other.log.9":  "other",
+	})
+	defer os.RemoveAll(dir)
+	defer w.Close()
+
+	w.histMu.Lock()
+	name := w.backupName()
+	w.histMu.Unlock()
+
+	if name != filepath.Join(dir, "app.log.4") {
+		t.Errorf("backup name %s, want app.log.4", name)
+	}
+	checkFiles(t, dir, map[string]string{
+		"app.log.1":    "one",
+		"app.log.3.gz": "three",
+	})
+}
+
+func TestBackupNameAscendingEmpty(t *testing.T) {
+	w, dir := newNamingWriter(t, BackupNameAscending, nil)
+	defer os.RemoveAll(dir)
+	defer w.Close()
+
+	w.histMu.Lock()
+	name := w.backupName()
+	w.histMu.Unlock()
+
+	if name != filepath.Join(dir, "app.log.1") {
+		t.Errorf("backup name %s, want app.log.1", name)
+	}
+}
+
+func TestBackupOrder(t *testing.T) {
+	tests := []struct {
+		naming string
+		suffix string
+		order  int
+	}{
+		{BackupNameShift, "1", -1},
+		{BackupNameShift, "3", -3},
+		{BackupNameAscending, "1", 1},
+		{BackupNameAscending, "3", 3},
+		{BackupNameAscending, "", 0},
+	}
+	for _, tt := range tests {
+		w := &RollWriter{opts: &Options{BackupNaming: tt.naming}}
+		if got := w.backupOrder(tt.suffix); got != tt.order {
+			t.Errorf("%s backupOrder(%q) = %d, want %d", tt.naming, tt.suffix, got, tt.order)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package rollwriter
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// 异步写入队列满时的处理策略
+const (
+	// OverflowBlock 阻塞直到队列有空位
+	OverflowBlock = "block"
+	// OverflowBlockTimeout 最多阻塞OverflowTimeout，超时后丢弃当前日志
+	OverflowBlockTimeout = "block_timeout"
+	// OverflowDropNewest 丢弃当前日志
+	OverflowDropNewest = "drop_newest"
+	// OverflowDropOldest 丢弃队列中最旧的日志，写入当前日志
+	OverflowDropOldest = "drop_oldest"
+	// OverflowLevel 按优先级处理，队列超过一半时丢弃低优先级日志，超过80%时丢弃普通优先级日志，高优先级日志阻塞等待
+	OverflowLevel = "level"
+	// OverflowSpill 写入本地溢出文件，队列空闲后按顺序重放到下层writer，进程重启后继续重放
+	OverflowSpill = "spill"
+)
+
+// defaultOverflowTimeout OverflowBlockTimeout默认的最长阻塞时间
+const defaultOverflowTimeout = 100 * time.Millisecond
+
+// Priority 日志的优先级，OverflowLevel策略下队列快满时先丢弃优先级低的日志
+type Priority int
+
+const (
+	// PriorityLow 如debug日志，队列超过一半时丢弃
+	PriorityLow Priority = iota
+	// PriorityNormal 如info日志，队列超过80%时丢弃，Write写入的日志都是这个优先级
+	PriorityNormal
+	// PriorityHigh 如warn、error日志，不丢弃
+	PriorityHigh
+)
+
+var errLogFull = errors.New("log is full, drop")
+
+// WithOverflowPolicy 设置队列满时的处理策略，不设置时CanDropLog为true按OverflowDropNewest，否则按OverflowBlock
+func WithOverflowPolicy(policy string) AsyncOption {
+	return func(opts *AsyncOptions) {
+		opts.OverflowPolicy = policy
+	}
+}
+
+// WithOverflowTimeout 设置OverflowBlockTimeout策略的最长阻塞时间
+func WithOverflowTimeout(d time.Duration) AsyncOption {
+	return func(opts *AsyncOptions) {
+		opts.OverflowTimeout = d
+	}
+}
+
+// WritePriority 按优先级写入，只有OverflowLevel策略区分优先级
+func (w *AsyncRollWriter) WritePriority(data []byte, p Priority) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return 0, ErrClosed
+	}
+
+	log := getLog(data)
+	if w.opts.OverflowPolicy == OverflowSpill {
+		if err := w.spillOrQueue(log); err != nil {
+			putLog(log)
+			atomic.AddUint64(&w.counters.dropped, 1)
+			return 0, err
+		}
+		atomic.AddUint64(&w.counters.accepted, 1)
+		return len(data), nil
+	}
+	if err := w.enqueue(log, p); err != nil {
+		putLog(log)
+		atomic.AddUint64(&w.counters.dropped, 1)
+		return 0, err
+	}
+	atomic.AddUint64(&w.counters.accepted, 1)
+	return len(data), nil
+}
+
+// enqueue 按策略放入队列，调用方持有读锁
+func (w *AsyncRollWriter) enqueue(log *[]byte, p Priority) error {
+	switch w.opts.OverflowPolicy {
+	case OverflowBlockTimeout:
+		select {
+		case w.logChan <- log:
+			return nil
+		default:
+		}
+		timer := time.NewTimer(w.opts.OverflowTimeout)
+		defer timer.Stop()
+		select {
+		case w.logChan <- log:
+			return nil
+		case <-timer.C:
+			return errLogFull
+		}
+	case OverflowDropNewest:
+		select {
+		case w.logChan <- log:
+			return nil
+		default:
+			return errLogFull
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case w.logChan <- log:
+				return nil
+			default:
+			}
+			select {
+			case old := <-w.logChan: // 丢弃最旧的一条
+				putLog(old)
+				atomic.AddUint64(&w.counters.dropped, 1)
+			default:
+			}
+		}
+	case OverflowLevel:
+		if p >= PriorityHigh {
+			w.logChan <- log
+			return nil
+		}
+		if len(w.logChan) >= w.levelLimit(p) {
+			return errLogFull
+		}
+		select {
+		case w.logChan <- log:
+			return nil
+		default:
+			return errLogFull
+		}
+	default:
+		w.logChan <- log
+		return nil
+	}
+}
+
+// levelLimit OverflowLevel策略下各优先级允许写入的队列长度上限
+func (w *AsyncRollWriter) levelLimit(p Priority) int {
+	if p == PriorityLow {
+		return cap(w.logChan) / 2
+	}
+	return cap(w.logChan) * 4 / 5
+}
@@ -0,0 +1,136 @@
+package rollwriter
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newBlockedWriter 创建每条日志立即写入的writer，写入第一条日志并等到写日志的协程阻塞在下层writer上
+func newBlockedWriter(t *testing.T, opt ...AsyncOption) (*AsyncRollWriter, *testWriter) {
+	tw := newTestWriter(true)
+	opts := append([]AsyncOption{WithWriteLogSize(1), WithDropNoticeInterval(-1)}, opt...)
+	w := NewAsyncRollWriter(tw, opts...)
+	fmt.Fprintln(w, 0)
+	select {
+	case <-tw.entered:
+	case <-time.After(time.Second):
+		t.Fatal("underlying writer not called")
+	}
+	return w, tw
+}
+
+func TestOverflowDrop(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   []string
+	}{
+		{OverflowDropNewest, seq(0, 5)},
+		{OverflowDropOldest, append([]string{"0"}, seq(7, 11)...)},
+		{OverflowBlockTimeout, seq(0, 5)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			w, tw := newBlockedWriter(t, WithLogQueueSize(4), WithOverflowPolicy(tt.policy),
+				WithOverflowTimeout(10*time.Millisecond))
+			for i := 1; i <= 10; i++ {
+				_, err := fmt.Fprintln(w, i)
+				if full := i > 4 && tt.policy != OverflowDropOldest; full != (err == errLogFull) {
+					t.Errorf("write %d err %v", i, err)
+				}
+			}
+			st := w.Stats()
+			if st.Dropped != 6 {
+				t.Errorf("dropped %d, want 6", st.Dropped)
+			}
+
+			close(tw.gate)
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if got := tw.lines(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lines %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverflowBlock(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  []AsyncOption
+	}{
+		{"block", []AsyncOption{WithOverflowPolicy(OverflowBlock)}},
+		{"default", nil},
+		{"unknown", []AsyncOption{WithOverflowPolicy("unknown")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, tw := newBlockedWriter(t, append([]AsyncOption{WithLogQueueSize(4)}, tt.opt...)...)
+			for i := 1; i <= 4; i++ {
+				fmt.Fprintln(w, i)
+			}
+
+			// 队列满时阻塞到有空位，不丢弃
+			done := make(chan struct{})
+			go func() {
+				fmt.Fprintln(w, 5)
+				close(done)
+			}()
+			select {
+			case <-done:
+				t.Fatal("write should block when queue is full")
+			case <-time.After(50 * time.Millisecond):
+			}
+			close(tw.gate)
+			<-done
+
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if got := tw.lines(); !reflect.DeepEqual(got, seq(0, 6)) {
+				t.Errorf("lines %v", got)
+			}
+		})
+	}
+}
+
+func TestOverflowCanDropLog(t *testing.T) {
+	w, tw := newBlockedWriter(t, WithLogQueueSize(1), WithCanDropLog(true))
+	fmt.Fprintln(w, 1)
+	if _, err := fmt.Fprintln(w, 2); err != errLogFull {
+		t.Errorf("write to full queue err %v, want errLogFull", err)
+	}
+	close(tw.gate)
+	w.Close()
+}
+
+func TestOverflowLevel(t *testing.T) {
+	w, tw := newBlockedWriter(t, WithLogQueueSize(10), WithOverflowPolicy(OverflowLevel))
+	defer w.Close()
+	defer close(tw.gate)
+
+	// 低优先级最多占一半，普通优先级最多占80%，高优先级不丢弃
+	tests := []struct {
+		priority Priority
+		writes   int
+		accepted int
+	}{
+		{PriorityLow, 10, 5},
+		{PriorityNormal, 10, 3},
+		{PriorityLow, 10, 0},
+		{PriorityHigh, 2, 2}, // 队列已满，再写入会阻塞
+	}
+	for _, tt := range tests {
+		accepted := 0
+		for i := 0; i < tt.writes; i++ {
+			if _, err := w.WritePriority([]byte("x\n"), tt.priority); err == nil {
+				accepted++
+			}
+		}
+		if accepted != tt.accepted {
+			t.Errorf("priority %d accepted %d, want %d", tt.priority, accepted, tt.accepted)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package rollwriter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// strftimeRegexp 把strftime格式转换成匹配对应文件名的正则，不包含^$
+func strftimeRegexp(format string) string {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' || i == len(format)-1 {
+			b.WriteString(regexp.QuoteMeta(string(c)))
+			continue
+		}
+
+		i++
+		switch format[i] {
+		case 'Y':
+			b.WriteString(`\d{4}`)
+		case 'm', 'd', 'H', 'M', 'S', 'y', 'I', 'C':
+			b.WriteString(`\d{2}`)
+		case 'e':
+			b.WriteString(`[ \d]\d`)
+		case 'j':
+			b.WriteString(`\d{3}`)
+		case 'L':
+			b.WriteString(`\d{3}`)
+		case 'u', 'w':
+			b.WriteString(`\d`)
+		case 'U', 'V', 'W':
+			b.WriteString(`\d{2}`)
+		case 'b', 'h', 'a':
+			b.WriteString(`[A-Za-z]{3}`)
+		case 'B', 'A':
+			b.WriteString(`[A-Za-z]+`)
+		case 'p':
+			b.WriteString(`[AP]M`)
+		case '%':
+			b.WriteString(`%`)
+		default:
+			b.WriteString(`.+?`)
+		}
+	}
+	return b.String()
+}
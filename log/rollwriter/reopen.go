@@ -0,0 +1,100 @@
+package rollwriter
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Syncer 重新打开文件前需要先刷新缓冲的writer，如AsyncRollWriter
+type Syncer interface {
+	Sync() error
+}
+
+var (
+	reopenMu      sync.Mutex
+	reopenWriters = map[*RollWriter]Syncer{}
+)
+
+// Register 注册RollWriter，ReopenAll时重新打开，buffer不为nil时先调用buffer.Sync刷新缓冲
+// RollWriter关闭时自动取消注册
+func Register(w *RollWriter, buffer Syncer) {
+	reopenMu.Lock()
+	defer reopenMu.Unlock()
+	reopenWriters[w] = buffer
+}
+
+// Unregister 取消注册
+func Unregister(w *RollWriter) {
+	reopenMu.Lock()
+	defer reopenMu.Unlock()
+	delete(reopenWriters, w)
+}
+
+// ReopenAll 刷新缓冲后重新打开所有注册的RollWriter的当前文件，返回第一个错误
+func ReopenAll() error {
+	reopenMu.Lock()
+	writers := make(map[*RollWriter]Syncer, len(reopenWriters))
+	for w, buffer := range reopenWriters {
+		writers[w] = buffer
+	}
+	reopenMu.Unlock()
+
+	var err error
+	for w, buffer := range writers {
+		if buffer != nil {
+			_ = buffer.Sync()
+		}
+		if e := w.Reopen(); e != nil && e != ErrClosed && err == nil { // 并发关闭的忽略
+			err = e
+		}
+	}
+	return err
+}
+
+// HandleSIGHUP 收到SIGHUP时调用ReopenAll，用于配合外部logrotate的create/copytruncate模式，返回停止监听的函数
+func HandleSIGHUP() (stop func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ch:
+				_ = ReopenAll()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+// Reopen 关闭并重新打开当前文件
+func (w *RollWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.isClosed() {
+		return ErrClosed
+	}
+
+	currPath := w.pattern.FormatString(time.Now())
+	rotated := ""
+	if w.currPath != currPath {
+		rotated = w.currPath
+		w.currPath = currPath
+		w.notifyClose()
+	}
+	return w.doReopenFile(w.currPath, rotated)
+}
@@ -0,0 +1,75 @@
+package rollwriter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newRotatedWriter 创建注册了异步缓冲的RollWriter，写入一条日志后模拟logrotate把当前文件移走
+func newRotatedWriter(t *testing.T, dir string) (*AsyncRollWriter, string) {
+	path := filepath.Join(dir, "app.log")
+	w, err := NewRollWriter(path, WithReopenInterval(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	async := NewAsyncRollWriter(w, WithWriteLogInterval(1000))
+	Register(w, async)
+
+	if _, err := w.Write(nil); err != nil { // 打开当前文件
+		t.Fatal(err)
+	}
+	if _, err := async.Write([]byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	return async, path
+}
+
+func TestReopenAll(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+
+	async, path := newRotatedWriter(t, dir)
+	defer async.Close()
+
+	// 重新打开前先刷新缓冲，缓冲中的日志写入移走的文件
+	if err := ReopenAll(); err != nil {
+		t.Fatalf("reopen all fail:%v", err)
+	}
+	if _, err := async.Write([]byte("b")); err != nil {
+		t.Fatal(err)
+	}
+	if err := async.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path+".1"); got != "a" {
+		t.Errorf("rotated file content %q, want a", got)
+	}
+	if got := readFile(t, path); got != "b" {
+		t.Errorf("reopened file content %q, want b", got)
+	}
+}
+
+func TestReopenAllClosed(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+
+	// 关闭时取消注册，不会重新创建文件
+	async, path := newRotatedWriter(t, dir)
+	if err := async.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReopenAll(); err != nil {
+		t.Errorf("reopen all after close fail:%v", err)
+	}
+	if fileExist(path) {
+		t.Error("closed writer reopened")
+	}
+	if w := async.logger.(*RollWriter); w.Reopen() != ErrClosed {
+		t.Error("reopen closed writer should return ErrClosed")
+	}
+}
@@ -0,0 +1,35 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package rollwriter
+
+import (
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestHandleSIGHUP(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+
+	async, path := newRotatedWriter(t, dir)
+	defer async.Close()
+
+	stop := HandleSIGHUP()
+	defer stop()
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !fileExist(path) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := readFile(t, path+".1"); got != "a" {
+		t.Errorf("rotated file content %q, want a", got)
+	}
+	if !fileExist(path) {
+		t.Error("file not reopened after SIGHUP")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
@@ -18,14 +19,45 @@ import (
 
 var _ io.WriteCloser = (*RollWriter)(nil)
 
-var reopenFileTime int64 = 10 // 10s为单位
+// ErrClosed 关闭后继续写入时返回
+var ErrClosed = errors.New("rollwriter: write to closed writer")
+
+var reopenFileTime = time.Second // 默认检查当前文件是否被删除、移走或者需要按时间滚动的间隔
+
+// 文件滚动类型
+const (
+	// RollBySize 按大小分割文件
+	RollBySize = "size"
+	// RollByTime 按时间分割文件，设置MaxSize时超过大小的文件以时间戳后缀备份
+	RollByTime = "time"
+	// RollByTimeAndSize 按时间分割文件，同一时间段内按大小分割成编号递增的多个文件 app.log2026101612.1 .2 ...
+	// 清理历史文件时同一时间段的文件作为一个整体
+	RollByTimeAndSize = "time_size"
+)
 
 type Options struct {
 	MaxSize    int64  // 日志文件最大大小
-	MaxHistory int    // 保留的最大文件数
+	MaxHistory int    // 保留的最大文件数，按时间和大小滚动时为保留的最大时间段数
 	MaxDay     int    // 日志最大保留时间
 	IfCompress bool   // 日志文件是否压缩
 	TimeFormat string // 按时间分割文件的时间格式
+	RollType   string // 文件滚动类型，不设置时根据TimeFormat判断按大小还是按时间
+
+	BackupNaming     string // 超过大小备份文件的命名方式，见BackupNameTimestamp等
+	BackupTimeFormat string // 按时间戳命名备份文件时的strftime格式
+
+	MaxTotalSize int64 // 当前文件和所有历史文件(包括压缩后的)的总大小上限，超过时从最旧的文件开始删除
+
+	MinFreeSpace      int64         // 磁盘最小剩余空间，为0时不检查
+	DiskCheckInterval time.Duration // 磁盘剩余空间检查间隔
+
+	ReopenInterval time.Duration // 检查当前文件是否需要重新打开的间隔
+	Inotify        bool          // 是否使用inotify监听文件删除和移动，仅linux支持
+
+	CurrentLink bool // 按时间滚动时是否在filePath维护指向当前文件的软链接
+
+	OnRotate   func(oldPath string) // 滚动出的文件关闭(压缩、归档)后的回调，参数为文件最终路径
+	ArchiveDir string               // 滚动出的文件移动到的归档目录，支持strftime格式按日期分目录
 }
 
 type Option func(*Options)
@@ -36,6 +68,13 @@ func WithMaxSize(size int64) Option {
 	}
 }
 
+// WithMaxTotalSize 设置日志文件总大小上限，单位MB
+func WithMaxTotalSize(size int64) Option {
+	return func(opt *Options) {
+		opt.MaxTotalSize = size * 1024 * 1024
+	}
+}
+
 func WithMaxDay(day int) Option {
 	return func(opt *Options) {
 		opt.MaxDay = day
@@ -60,22 +99,74 @@ func WithTimeFormat(s string) Option {
 	}
 }
 
+func WithRollType(t string) Option {
+	return func(opt *Options) {
+		opt.RollType = t
+	}
+}
+
+// WithReopenInterval 设置检查当前文件是否被删除、移走的间隔，文件没有变化时不会重新打开
+func WithReopenInterval(d time.Duration) Option {
+	return func(opt *Options) {
+		opt.ReopenInterval = d
+	}
+}
+
+// WithInotify 使用inotify监听日志目录，文件被删除或移走后下一次写入立即重新打开，仅linux支持
+func WithInotify(b bool) Option {
+	return func(opt *Options) {
+		opt.Inotify = b
+	}
+}
+
+func WithBackupNaming(naming string) Option {
+	return func(opt *Options) {
+		opt.BackupNaming = naming
+	}
+}
+
+func WithBackupTimeFormat(format string) Option {
+	return func(opt *Options) {
+		opt.BackupTimeFormat = format
+	}
+}
+
 type RollWriter struct {
 	filePath string   // 文件路径
 	opts     *Options // 配置
 
 	pattern  *strftime.Strftime // 文件模式
+	archive  *strftime.Strftime // 归档目录
+	backup   *strftime.Strftime // 按时间戳命名的备份文件后缀
+	history  *regexp.Regexp     // 匹配历史文件，分组1为时间段文件名，分组2为备份后缀
 	currDir  string
 	currPath string
 	currSize int64
 	currFile atomic.Value
+	currStat atomic.Value // 当前打开文件的FileInfo，用于判断路径是否还指向该文件
+	openErr  atomic.Value // openResult 最近一次打开文件的错误，打不开文件时Write返回该错误
 
-	openTime int64
+	openTime int64        // 上次检查当前文件的时间 UnixNano
+	checkNow int32        // 目录中有文件被删除或移走时置1，下一次写入时立即检查
+	watcher  *fileWatcher // inotify监听
+	linkWarn sync.Once    // filePath不是软链接时只提示一次
 
 	mu       sync.Mutex
 	once     sync.Once
-	closeCh  chan *os.File // 待关闭的文件句柄
-	notifyCh chan bool     // 触发日志清理
+	closeCh  chan closedFile // 待关闭的文件句柄，持有mu时发送
+	notifyCh chan bool       // 触发日志清理，持有mu时或者在cleanClosedFile中发送
+
+	closed      int32         // Close后置1
+	closedDone  chan struct{} // cleanClosedFile退出
+	expiredDone chan struct{} // cleanExpireFile退出
+
+	needCompress int32         // 有文件句柄关闭后置1，清理时压缩历史文件
+	histMu       sync.Mutex    // 历史文件的重命名、压缩和删除互斥，避免编号后移时文件被压缩或删除
+	pending      []os.FileInfo // 已经滚动、句柄还没有关闭的文件，不能压缩，受histMu保护
+
+	diskCheckTime int64 // 上次检查磁盘剩余空间的时间 UnixNano
+	needFreeSpace int32 // 剩余空间不足时置1，清理协程删除历史文件
+	lowSpace      int32 // 清理后剩余空间仍然不足时置1
 }
 
 // 获取当前日志句柄
@@ -98,10 +189,12 @@ func dirExist(dir string) bool {
 
 func NewRollWriter(filePath string, opt ...Option) (*RollWriter, error) {
 	opts := &Options{
-		MaxSize:    0,
-		MaxDay:     0,
-		MaxHistory: 0,
-		IfCompress: false,
+		MaxSize:           0,
+		MaxDay:            0,
+		MaxHistory:        0,
+		IfCompress:        false,
+		DiskCheckInterval: defaultDiskCheckInterval,
+		ReopenInterval:    reopenFileTime,
 	}
 
 	for _, o := range opt {
@@ -112,104 +205,231 @@ func NewRollWriter(filePath string, opt ...Option) (*RollWriter, error) {
 		return nil, errors.New("no file path")
 	}
 
+	if opts.RollType == "" {
+		opts.RollType = RollBySize
+		if opts.TimeFormat != "" {
+			opts.RollType = RollByTime
+		}
+	}
+
 	pattern, err := strftime.New(filePath + opts.TimeFormat)
 	if err != nil {
 		return nil, err
 	}
 
 	write := &RollWriter{
-		filePath: filePath,
-		opts:     opts,
-		pattern:  pattern,
-		currDir:  filepath.Dir(filePath),
+		filePath:     filePath,
+		opts:         opts,
+		pattern:      pattern,
+		currDir:      filepath.Dir(filePath),
+		needCompress: 1, // 第一次清理时压缩上次进程退出前没有压缩的文件
+	}
+
+	if err := write.initBackupNaming(); err != nil {
+		return nil, err
+	}
+
+	if opts.ArchiveDir != "" {
+		if write.archive, err = strftime.New(opts.ArchiveDir); err != nil {
+			return nil, err
+		}
 	}
 
 	// 如果文件已经存在，则直接返回成功，否则需要创建文件
-	if dirExist(write.currDir) {
-		return write, nil
+	if !dirExist(write.currDir) {
+		if err = os.Mkdir(write.currDir, 0755); err != nil {
+			return nil, err
+		}
 	}
-	err = os.Mkdir(write.currDir, 0755)
-	if err != nil {
-		return nil, err
+
+	if opts.Inotify {
+		// 不支持时退化为定期检查
+		write.watcher, err = newFileWatcher(write.currDir, func() {
+			atomic.StoreInt32(&write.checkNow, 1)
+		})
+		if err != nil {
+			fmt.Printf("[NewRollWriter]watch %s failed:%+v\n", write.currDir, err)
+		}
 	}
 
 	return write, nil
 }
 
-func (w *RollWriter) doReopenFile(path string) error {
-	atomic.StoreInt64(&w.openTime, time.Now().Unix())
+// openResult 打开文件的结果，atomic.Value不能保存nil
+type openResult struct {
+	err error
+}
+
+// doReopenFile 打开path，rotated不为空时为滚动出的旧文件的路径，关闭后压缩、归档并回调
+func (w *RollWriter) doReopenFile(path, rotated string) error {
+	atomic.StoreInt64(&w.openTime, time.Now().UnixNano())
 
 	lastFile := w.getCurrFile()
 
 	// 打开新的文件，如果不存在则创建
 	curFile, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	w.openErr.Store(openResult{err: err})
 	if err == nil {
 		w.setCurrFile(curFile)
 
 		if lastFile != nil {
-			// 需要延迟关闭
-			w.closeCh <- lastFile
+			// 需要延迟关闭，记录stat用于文件被改名后查找
+			st, _ := lastFile.Stat()
+			w.addPending(st)
+			w.closeCh <- closedFile{file: lastFile, rotated: rotated, stat: st}
 		}
 
-		st, _ := os.Stat(path)
+		st, _ := curFile.Stat()
 		if st != nil {
+			w.currStat.Store(st)
 			atomic.StoreInt64(&w.currSize, st.Size())
 		}
+
+		w.updateCurrentLink()
 	}
 
 	return err
 }
 
-// 定期重新打开文件
+// needReopen 没有打开文件、目录有变化或者到了检查间隔时需要检查当前文件
+func (w *RollWriter) needReopen() bool {
+	if w.getCurrFile() == nil || atomic.LoadInt32(&w.checkNow) == 1 {
+		return true
+	}
+	return time.Now().UnixNano()-atomic.LoadInt64(&w.openTime) > int64(w.opts.ReopenInterval)
+}
+
+// sameFile 当前路径是否还指向打开的文件，比较设备号和inode
+func (w *RollWriter) sameFile() bool {
+	last, ok := w.currStat.Load().(os.FileInfo)
+	if !ok {
+		return false
+	}
+	st, err := os.Stat(w.currPath)
+	return err == nil && os.SameFile(last, st)
+}
+
+// 按时间滚动到新文件，或者当前文件被删除、移走时重新打开文件
 func (w *RollWriter) reopenFile() {
-	if w.getCurrFile() == nil || time.Now().Unix()-atomic.LoadInt64(&w.openTime) > 10 {
-		now := time.Now()
-		atomic.StoreInt64(&w.openTime, now.Unix())
-		currPath := w.pattern.FormatString(time.Now())
-		if w.currPath != currPath { // 如果文件已经更新，
-			w.currPath = currPath
-			w.notifyClose()
-		}
+	if !w.needReopen() {
+		return
+	}
 
-		_ = w.doReopenFile(w.currPath)
+	now := time.Now()
+	atomic.StoreInt64(&w.openTime, now.UnixNano())
+	atomic.StoreInt32(&w.checkNow, 0)
+	currPath := w.pattern.FormatString(now)
+	rotated := ""
+	if w.currPath != currPath { // 如果文件已经更新，
+		rotated = w.currPath
+		w.currPath = currPath
+		w.notifyClose()
+	} else if w.getCurrFile() != nil && w.sameFile() { // 文件没有变化，继续写入
+		return
 	}
+
+	_ = w.doReopenFile(w.currPath, rotated)
 }
 
 func (w *RollWriter) Write(v []byte) (n int, err error) {
-	// 每隔10s重新打开一次文件
-	if w.getCurrFile() == nil || time.Now().Unix()-atomic.LoadInt64(&w.openTime) > 10 {
+	if w.isClosed() {
+		return 0, ErrClosed
+	}
+
+	// 文件不存在、被删除或移走、或者需要按时间滚动时重新打开
+	if w.needReopen() {
 		w.mu.Lock()
-		w.reopenFile()
+		if !w.isClosed() {
+			w.reopenFile()
+		}
 		w.mu.Unlock()
 	}
 
 	// 获取当前文件句柄
 	if w.getCurrFile() == nil {
+		if w.isClosed() {
+			return 0, ErrClosed
+		}
+		if r, ok := w.openErr.Load().(openResult); ok && r.err != nil {
+			return 0, r.err // 如权限不足，上层据此判断是否重试
+		}
 		return 0, errors.New("curr file not exist")
 	}
 
+	if w.opts.MinFreeSpace > 0 {
+		w.checkDiskSpace()
+	}
+
 	// 写文件
 	n, err = w.getCurrFile().Write(v)
 	atomic.AddInt64(&w.currSize, int64(n))
+	if err != nil && w.isClosed() { // 写入过程中被关闭
+		return n, ErrClosed
+	}
 
 	// 如果设置最大文件大小，则另开文件存储
 	// 如果上面是err也会触发检查
 	if w.opts.MaxSize > 0 && atomic.LoadInt64(&w.currSize) >= w.opts.MaxSize {
 		w.mu.Lock()
-		w.backupFile()
+		if !w.isClosed() {
+			w.backupFile()
+		}
 		w.mu.Unlock()
 	}
 
 	return n, err
 }
 
+// Sync 把当前文件刷到磁盘
+func (w *RollWriter) Sync() error {
+	if f := w.getCurrFile(); f != nil {
+		return f.Sync()
+	}
+	return nil
+}
+
+func (w *RollWriter) isClosed() bool {
+	return atomic.LoadInt32(&w.closed) == 1
+}
+
+// Close 关闭当前文件，等待待关闭的文件句柄全部关闭、清理协程退出后再做一次清理，之后的写入返回ErrClosed
+// 重复调用直接返回nil
 func (w *RollWriter) Close() error {
-	if w.getCurrFile() == nil {
+	Unregister(w)
+
+	w.mu.Lock()
+	if w.isClosed() {
+		w.mu.Unlock()
 		return nil
 	}
+	atomic.StoreInt32(&w.closed, 1)
+
+	var err error
+	if f := w.getCurrFile(); f != nil {
+		err = f.Close()
+		w.setCurrFile(nil)
+	}
+
+	// 关闭后不会再启动清理协程，也不会再发送closeCh和notifyCh
+	started := false
+	if w.closeCh != nil {
+		started = true
+		close(w.closeCh)
+	}
+	w.mu.Unlock()
+
+	if w.watcher != nil {
+		w.watcher.Close()
+	}
 
-	err := w.getCurrFile().Close()
-	w.setCurrFile(nil)
+	if started {
+		<-w.closedDone // 剩下的文件句柄全部关闭，滚动出的文件处理完
+		close(w.notifyCh)
+		<-w.expiredDone
+	}
+
+	// 最后一次压缩和清理
+	w.cleanHistory()
 	return err
 }
 
@@ -219,13 +439,18 @@ func (w *RollWriter) backupFile() {
 		atomic.StoreInt64(&w.currSize, 0) // todo  为啥设置当前size为0不放在重开文件后
 
 		// 修改老文件名字
-		newFileName := w.currPath + "." + time.Now().Format("bk-20060102-150405.00000")
+		rotated := ""
 		if _, e := os.Stat(w.currPath); !os.IsNotExist(e) { // todo 为啥需要先查文件stat
-			_ = os.Rename(w.currPath, newFileName)
+			w.histMu.Lock()
+			name := w.backupName()
+			if os.Rename(w.currPath, name) == nil {
+				rotated = name
+			}
+			w.histMu.Unlock()
 		}
 
 		// 重新开新文件
-		_ = w.doReopenFile(w.currPath)
+		_ = w.doReopenFile(w.currPath, rotated)
 		w.notifyClose()
 	}
 
@@ -236,7 +461,9 @@ func (w *RollWriter) backupFile() {
 func (w *RollWriter) notifyClose() {
 	w.once.Do(func() {
 		w.notifyCh = make(chan bool, 1)
-		w.closeCh = make(chan *os.File, 100)
+		w.closeCh = make(chan closedFile, 100)
+		w.closedDone = make(chan struct{})
+		w.expiredDone = make(chan struct{})
 
 		go w.cleanClosedFile()
 		go w.cleanExpireFile()
@@ -249,20 +476,51 @@ func (w *RollWriter) notifyClose() {
 }
 
 func (w *RollWriter) cleanClosedFile() {
+	defer close(w.closedDone)
+
 	for f := range w.closeCh {
 		time.Sleep(30 * time.Millisecond)
-		f.Close()
+		f.file.Close()
+		w.removePending(f.stat)
+
+		if f.rotated != "" {
+			w.handleRotated(f)
+		}
+
+		// 文件句柄关闭后不会再有写入，可以压缩
+		if w.opts.IfCompress {
+			atomic.StoreInt32(&w.needCompress, 1)
+			select {
+			case w.notifyCh <- true:
+			default:
+			}
+		}
 	}
 }
 
 func (w *RollWriter) cleanExpireFile() {
+	defer close(w.expiredDone)
+
 	for _ = range w.notifyCh {
-		if w.opts.MaxHistory == 0 && w.opts.MaxDay == 0 { //这种情况下不清理历史日志
-			continue
-		}
+		w.cleanHistory()
+	}
+}
+
+// cleanHistory 按需释放磁盘空间、压缩历史文件、清理过期文件
+func (w *RollWriter) cleanHistory() {
+	if atomic.CompareAndSwapInt32(&w.needFreeSpace, 1, 0) {
+		w.freeDiskSpace()
+	}
 
-		w.expireFile()
+	if w.opts.IfCompress && atomic.CompareAndSwapInt32(&w.needCompress, 1, 0) {
+		w.compressHistory()
 	}
+
+	if w.opts.MaxHistory == 0 && w.opts.MaxDay == 0 && w.opts.MaxTotalSize == 0 { //这种情况下不清理历史日志
+		return
+	}
+
+	w.expireFile()
 }
 
 func (w *RollWriter) expireFile() {
@@ -272,19 +530,43 @@ func (w *RollWriter) expireFile() {
 		return
 	}
 
+	// 当前时间段的文件不按个数和时间清理
+	currGroup := filepath.Base(w.currPath)
+	var currFiles, remain []logWithT
+	for _, f := range oldFiles {
+		if f.group == currGroup {
+			currFiles = append(currFiles, f)
+		} else {
+			remain = append(remain, f)
+		}
+	}
+	oldFiles = remain
+
 	var remove []logWithT
 
 	oldFiles = expireWithMaxHistory(oldFiles, &remove, w.opts.MaxHistory)
 
 	oldFiles = expireWithDay(oldFiles, &remove, w.opts.MaxDay)
 
+	// 总大小是硬性限制，当前时间段的文件也参与
+	if w.opts.MaxTotalSize > 0 {
+		oldFiles = append(oldFiles, currFiles...)
+		sort.Sort(byModTimeLogInfo(oldFiles))
+		oldFiles = expireWithMaxTotalSize(oldFiles, &remove, w.opts.MaxTotalSize, atomic.LoadInt64(&w.currSize))
+	}
+
+	w.histMu.Lock()
 	w.removeFile(remove)
+	w.histMu.Unlock()
 
 }
 
 func (w *RollWriter) removeFile(remove []logWithT) {
 	for _, f := range remove {
-		os.Remove(filepath.Join(w.currDir, f.Name()))
+		os.Remove(filepath.Join(f.dir, f.Name()))
+		if strings.HasSuffix(f.Name(), compressSuffix) { // 压缩后没来得及删除的原文件
+			os.Remove(filepath.Join(f.dir, strings.TrimSuffix(f.Name(), compressSuffix)))
+		}
 	}
 }
 
@@ -297,7 +579,7 @@ func expireWithMaxHistory(files []logWithT, remove *[]logWithT, maxHistory int)
 	var remain []logWithT
 	preserved := make(map[string]bool)
 	for _, f := range files {
-		preserved[f.Name()] = true
+		preserved[f.group] = true
 		if len(preserved) > maxHistory {
 			*remove = append(*remove, f)
 		} else {
@@ -308,16 +590,41 @@ func expireWithMaxHistory(files []logWithT, remove *[]logWithT, maxHistory int)
 	return remain
 }
 
+// 当前文件和历史文件的总大小超过上限后，从最旧的文件开始删除，files按时间从新到旧排序
+func expireWithMaxTotalSize(files []logWithT, remove *[]logWithT, maxTotalSize, currSize int64) []logWithT {
+	total := currSize
+	for _, f := range files {
+		total += f.Size()
+	}
+
+	n := len(files)
+	for n > 0 && total > maxTotalSize {
+		n--
+		total -= files[n].Size()
+		*remove = append(*remove, files[n])
+	}
+
+	return files[:n]
+}
+
 // 超过最大日期后，即删除
 func expireWithDay(files []logWithT, remove *[]logWithT, maxDay int) []logWithT {
 	if maxDay == 0 {
 		return files
 	}
 
+	// 同一组的文件按组内最新的修改时间判断
+	newest := make(map[string]time.Time)
+	for _, f := range files {
+		if f.modTime.After(newest[f.group]) {
+			newest[f.group] = f.modTime
+		}
+	}
+
 	var remain []logWithT
 	detTs := time.Now().Add(-1 * time.Duration(int64(24*time.Hour)*int64(maxDay)))
 	for _, f := range files {
-		if f.modTime.Before(detTs) {
+		if newest[f.group].Before(detTs) {
 			*remove = append(*remove, f)
 		} else {
 			remain = append(remain, f)
@@ -327,7 +634,8 @@ func expireWithDay(files []logWithT, remove *[]logWithT, maxDay int) []logWithT
 	return remain
 }
 
-// 查找目录下与当前文件匹配的文件
+// 查找目录下与当前文件匹配的文件，设置归档目录时包括归档目录下的文件
+// 压缩中的临时文件不计入，压缩完成但原文件还没删除时只计压缩后的文件
 func (w *RollWriter) getDirHistory() ([]logWithT, error) {
 	files, err := ioutil.ReadDir(w.currDir)
 
@@ -335,8 +643,21 @@ func (w *RollWriter) getDirHistory() ([]logWithT, error) {
 		return nil, fmt.Errorf("can not read dir files:%+v", err)
 	}
 
+	logWithTs := w.matchHistory(w.currDir, files)
+	logWithTs = append(logWithTs, w.archiveHistory()...)
+
+	sort.Sort(byModTimeLogInfo(logWithTs)) // 对匹配当前文件的历史文件按时间排序
+	return logWithTs, nil
+}
+
+// matchHistory 找出dir目录下的文件中与当前文件匹配的历史文件
+func (w *RollWriter) matchHistory(dir string, files []os.FileInfo) []logWithT {
 	logWithTs := make([]logWithT, 0)
-	fileName := filepath.Base(w.filePath) // 文件名
+
+	names := make(map[string]bool, len(files))
+	for _, f := range files {
+		names[f.Name()] = true
+	}
 
 	for _, f := range files { // 遍历目录下的文件，找到和当前文件匹配的文件
 		if f.IsDir() {
@@ -347,33 +668,50 @@ func (w *RollWriter) getDirHistory() ([]logWithT, error) {
 			continue
 		}
 
-		if !strings.HasPrefix(f.Name(), fileName) { // 如果不是同样的前缀，则说明不是相同log生成的文件
+		if w.isCurrentLink(f.Name()) { // 指向当前文件的软链接
 			continue
 		}
 
-		st, _ := os.Stat(filepath.Join(w.currDir, fileName))
-		if st == nil { // 取不到文件属性
+		m := w.history.FindStringSubmatch(f.Name())
+		if m == nil { // 按命名方式解析不了，则说明不是相同log生成的文件
 			continue
 		}
 
+		if names[f.Name()+compressSuffix] { // 已经有压缩后的文件
+			continue
+		}
+
+		group := f.Name()
+		if w.opts.RollType == RollByTimeAndSize { // 同一时间段的多个文件为一组
+			group = m[1]
+		}
+
 		logWithTs = append(logWithTs, logWithT{
-			modTime:  st.ModTime(),
+			modTime:  f.ModTime(),
+			group:    group,
+			order:    w.backupOrder(m[2]),
+			dir:      dir,
 			FileInfo: f,
 		})
 	}
 
-	sort.Sort(byModTimeLogInfo(logWithTs)) // 对匹配当前文件的历史文件按时间排序
-	return logWithTs, nil
+	return logWithTs
 }
 
 type logWithT struct {
 	modTime time.Time
+	dir     string // 所在目录，当前目录或者归档目录
+	group   string // 一起清理的文件分组
+	order   int    // 修改时间相同时的顺序，越大越新
 	os.FileInfo
 }
 
 type byModTimeLogInfo []logWithT
 
 func (b byModTimeLogInfo) Less(i, j int) bool {
+	if b[i].modTime.Equal(b[j].modTime) {
+		return b[i].order > b[j].order
+	}
 	return b[i].modTime.After(b[j].modTime)
 }
 
@@ -0,0 +1,308 @@
+package rollwriter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeFileInfo 只用到名称和大小的FileInfo
+type fakeFileInfo struct {
+	os.FileInfo
+	name string
+	size int64
+}
+
+func (f fakeFileInfo) Name() string { return f.name }
+func (f fakeFileInfo) Size() int64  { return f.size }
+
+func newLogWithT(name, group string, size int64) logWithT {
+	return logWithT{group: group, FileInfo: fakeFileInfo{name: name, size: size}}
+}
+
+func names(files []logWithT) []string {
+	s := make([]string, 0, len(files))
+	for _, f := range files {
+		s = append(s, f.Name())
+	}
+	return s
+}
+
+func TestExpireWithMaxHistory(t *testing.T) {
+	// 按时间从新到旧排序，同一时间段的文件为一组
+	files := []logWithT{
+		newLogWithT("app.log.20261016.2", "app.log.20261016", 1),
+		newLogWithT("app.log.20261016.1", "app.log.20261016", 1),
+		newLogWithT("app.log.20261015.1", "app.log.20261015", 1),
+		newLogWithT("app.log.20261014.2", "app.log.20261014", 1),
+		newLogWithT("app.log.20261014.1", "app.log.20261014", 1),
+	}
+
+	tests := []struct {
+		maxHistory int
+		remain     []string
+		remove     []string
+	}{
+		{0, names(files), []string{}},
+		{3, names(files), []string{}},
+		{2, names(files[:3]), names(files[3:])},
+		{1, names(files[:2]), names(files[2:])},
+	}
+	for _, tt := range tests {
+		remove := make([]logWithT, 0)
+		remain := expireWithMaxHistory(files, &remove, tt.maxHistory)
+		if !reflect.DeepEqual(names(remain), tt.remain) || !reflect.DeepEqual(names(remove), tt.remove) {
+			t.Errorf("maxHistory %d remain %v remove %v, want %v %v",
+				tt.maxHistory, names(remain), names(remove), tt.remain, tt.remove)
+		}
+	}
+}
+
+func TestExpireWithMaxHistoryNoGroup(t *testing.T) {
+	files := []logWithT{
+		newLogWithT("app.log.3", "app.log.3", 1),
+		newLogWithT("app.log.2", "app.log.2", 1),
+		newLogWithT("app.log.1", "app.log.1", 1),
+	}
+
+	remove := make([]logWithT, 0)
+	remain := expireWithMaxHistory(files, &remove, 2)
+	if !reflect.DeepEqual(names(remain), []string{"app.log.3", "app.log.2"}) ||
+		!reflect.DeepEqual(names(remove), []string{"app.log.1"}) {
+		t.Errorf("remain %v remove %v", names(remain), names(remove))
+	}
+}
+
+func TestExpireWithMaxTotalSize(t *testing.T) {
+	files := []logWithT{
+		newLogWithT("app.log.3", "app.log.3", 10),
+		newLogWithT("app.log.2", "app.log.2", 20),
+		newLogWithT("app.log.1", "app.log.1", 30),
+	}
+
+	tests := []struct {
+		maxTotalSize int64
+		currSize     int64
+		remain       []string
+		remove       []string
+	}{
+		{100, 15, names(files), []string{}},
+		{75, 15, names(files), []string{}},
+		{50, 15, names(files[:2]), []string{"app.log.1"}},
+		{30, 15, names(files[:1]), []string{"app.log.1", "app.log.2"}},
+		{10, 15, []string{}, []string{"app.log.1", "app.log.2", "app.log.3"}},
+	}
+	for _, tt := range tests {
+		remove := make([]logWithT, 0)
+		remain := expireWithMaxTotalSize(files, &remove, tt.maxTotalSize, tt.currSize)
+		if !reflect.DeepEqual(names(remain), tt.remain) || !reflect.DeepEqual(names(remove), tt.remove) {
+			t.Errorf("maxTotalSize %d remain %v remove %v, want %v %v",
+				tt.maxTotalSize, names(remain), names(remove), tt.remain, tt.remove)
+		}
+	}
+}
+
+func TestHistoryGroupByTime(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rollwriter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// 按时间和大小滚动，同一天的文件为一组
+	now := time.Now()
+	for i, name := range []string{"app.log.20201014.1", "app.log.20201014.2", "app.log.20201015.1", "other.log.20201015.1"} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mt := now.Add(time.Duration(i-10) * time.Hour)
+		_ = os.Chtimes(path, mt, mt)
+	}
+
+	w, err := NewRollWriter(filepath.Join(dir, "app.log"), WithRollType(RollByTimeAndSize), WithTimeFormat(".%Y%m%d"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	files, err := w.getDirHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups := make(map[string]string)
+	for _, f := range files {
+		groups[f.Name()] = f.group
+	}
+	want := map[string]string{
+		"app.log.20201014.1": "app.log.20201014",
+		"app.log.20201014.2": "app.log.20201014",
+		"app.log.20201015.1": "app.log.20201015",
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("groups %v, want %v", groups, want)
+	}
+
+	remove := make([]logWithT, 0)
+	expireWithMaxHistory(files, &remove, 1)
+	if got := names(remove); !reflect.DeepEqual(got, []string{"app.log.20201014.2", "app.log.20201014.1"}) {
+		t.Errorf("remove %v", got)
+	}
+}
+
+func TestWriteOpenError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rollwriter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// 日志路径是目录，打开失败
+	path := filepath.Join(dir, "app.log")
+	if err := os.Mkdir(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewRollWriter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	_, err = w.Write([]byte("x"))
+	if _, ok := err.(*os.PathError); !ok {
+		t.Errorf("write err %v, want the open error", err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s fail:%v", path, err)
+	}
+	return string(data)
+}
+
+func TestReopenByInode(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(path string) error
+		want   string // 当前路径的文件内容
+		moved  string // 原来的文件移动到的路径后缀，为空时不检查
+	}{
+		{"unchanged", func(string) error { return nil }, "ab", ""},
+		{"removed", os.Remove, "b", ""},
+		{"moved", func(path string) error { return os.Rename(path, path+".old") }, "b", ".old"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer removeAll(dir)
+
+			path := filepath.Join(dir, "app.log")
+			w, err := NewRollWriter(path, WithReopenInterval(10*time.Millisecond))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer w.Close()
+
+			if _, err := w.Write([]byte("a")); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.change(path); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(20 * time.Millisecond)
+			if _, err := w.Write([]byte("b")); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := readFile(t, path); got != tt.want {
+				t.Errorf("content %q, want %q", got, tt.want)
+			}
+			if tt.moved != "" {
+				if got := readFile(t, path+tt.moved); got != "a" {
+					t.Errorf("moved file content %q, want a", got)
+				}
+			}
+		})
+	}
+}
+
+func TestRollWriterClose(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+
+	w, err := NewRollWriter(filepath.Join(dir, "app.log"), withMaxBytes(3), WithBackupNaming(BackupNameAscending),
+		WithMaxHistory(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"aaa", "bbb", "ccc"} { // 滚动时启动清理协程
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close fail:%v", err)
+	}
+
+	for name, done := range map[string]chan struct{}{"cleanClosedFile": w.closedDone, "cleanExpireFile": w.expiredDone} {
+		select {
+		case <-done:
+		default:
+			t.Errorf("%s not exited after close", name)
+		}
+	}
+	// 关闭后做最后一次清理
+	if left, _ := filepath.Glob(filepath.Join(dir, "app.log.*")); !reflect.DeepEqual(left, []string{filepath.Join(dir, "app.log.3")}) {
+		t.Errorf("history after close %v", left)
+	}
+
+	if _, err := w.Write([]byte("x")); err != ErrClosed {
+		t.Errorf("write after close err %v, want ErrClosed", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("close twice err %v", err)
+	}
+}
+
+func TestRollWriterCloseConcurrent(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+
+	w, err := NewRollWriter(filepath.Join(dir, "app.log"), withMaxBytes(4096), WithBackupNaming(BackupNameAscending),
+		WithMaxHistory(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if _, err := w.Write([]byte("concurrent\n")); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	if err := w.Close(); err != nil {
+		t.Errorf("close fail:%v", err)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != ErrClosed {
+			t.Errorf("write err %v, want ErrClosed", err)
+		}
+	}
+}
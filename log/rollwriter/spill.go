@@ -0,0 +1,325 @@
+package rollwriter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// spillSuffix 没有设置溢出文件路径时，使用下层RollWriter的文件路径加该后缀
+	spillSuffix = ".spill"
+	// spillReadSize 每次从溢出文件读取的大小
+	spillReadSize = 64 * 1024
+	// spillHeaderSize 每条日志前的长度
+	spillHeaderSize = 4
+	// spillOffsetSize 文件开头保存的已重放位置
+	spillOffsetSize = 8
+)
+
+var errSpillFull = errors.New("spill file is full, drop")
+
+// WithSpillPath 设置OverflowSpill策略的溢出文件路径
+func WithSpillPath(path string) AsyncOption {
+	return func(opts *AsyncOptions) {
+		opts.SpillPath = path
+	}
+}
+
+// WithSpillMaxSize 设置溢出文件的大小上限，单位MB，超过后丢弃日志，为0时不限制
+func WithSpillMaxSize(size int64) AsyncOption {
+	return func(opts *AsyncOptions) {
+		opts.SpillMaxSize = size * 1024 * 1024
+	}
+}
+
+// spillFile 队列满时暂存日志的溢出文件
+// 文件开头8字节为已重放到的位置，之后每条日志为4字节长度加内容
+// 有未重放的日志时所有新日志都写入溢出文件，重放完后才重新进入队列，保证顺序
+// 文件加锁独占，热加载时新的writer等旧的writer重放完、释放锁后才使用，拿到锁之前队列满时阻塞
+type spillFile struct {
+	mu      sync.Mutex
+	f       *os.File // 拿到锁之前为nil
+	path    string
+	maxSize int64
+	active  int32  // 有未重放的日志时置1
+	size    int64  // 文件大小
+	readOff int64  // 已经重放到的位置
+	rbuf    []byte // 读取缓冲，只在写日志的协程中使用
+	warned  bool   // 打开失败只提示一次
+}
+
+// openSpill 打开溢出文件，上次进程退出前没有重放完的日志会在之后重放
+func openSpill(path string, maxSize int64) (*spillFile, error) {
+	s := &spillFile{path: path, maxSize: maxSize}
+	if _, err := s.acquire(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// spillPath 溢出文件路径，没有设置时使用下层RollWriter的路径
+func spillPath(opts *AsyncOptions, logger interface{}) string {
+	if opts.SpillPath != "" {
+		return opts.SpillPath
+	}
+	if rw, ok := logger.(*RollWriter); ok && rw != nil {
+		return rw.filePath + spillSuffix
+	}
+	return ""
+}
+
+// acquire 打开并锁定溢出文件，其他writer持有锁时返回false，由写日志的协程定期重试
+func (s *spillFile) acquire() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.f != nil {
+		return true, nil
+	}
+
+	f, err := os.OpenFile(s.path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return false, err
+	}
+	if ok, err := lockFile(f); !ok || err != nil {
+		f.Close()
+		return false, err
+	}
+
+	// 拿到锁之前文件可能已经被上一个持有者重放完删除
+	st, err := f.Stat()
+	curr, currErr := os.Stat(s.path)
+	if err != nil || currErr != nil || !os.SameFile(st, curr) {
+		f.Close()
+		return false, nil
+	}
+
+	s.f = f
+	s.size = st.Size()
+	if s.size < spillOffsetSize {
+		s.reset()
+		return true, nil
+	}
+
+	var off [spillOffsetSize]byte
+	if _, err := f.ReadAt(off[:], 0); err != nil {
+		s.reset()
+		return true, nil
+	}
+	s.readOff = int64(binary.BigEndian.Uint64(off[:]))
+	if s.readOff < spillOffsetSize || s.readOff > s.size { // 位置损坏时全部重放
+		s.readOff = spillOffsetSize
+	}
+	if s.readOff < s.size {
+		atomic.StoreInt32(&s.active, 1)
+	}
+	return true, nil
+}
+
+// tryAcquire 还没有拿到锁时重试，只在写日志的协程中调用
+func (s *spillFile) tryAcquire() {
+	if _, err := s.acquire(); err != nil && !s.warned {
+		s.warned = true
+		fmt.Printf("[spillFile]open spill file %s failed:%+v\n", s.path, err)
+	}
+}
+
+// locked 是否已经拿到溢出文件的锁
+func (s *spillFile) locked() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f != nil
+}
+
+// write 追加一条日志，写入失败时截断已经写入的部分，调用方持有mu
+func (s *spillFile) write(data []byte) error {
+	rec := make([]byte, spillHeaderSize+len(data))
+	if s.maxSize > 0 && s.size+int64(len(rec)) > s.maxSize {
+		return errSpillFull
+	}
+	binary.BigEndian.PutUint32(rec, uint32(len(data)))
+	copy(rec[spillHeaderSize:], data)
+
+	n, err := s.f.WriteAt(rec, s.size)
+	if err != nil {
+		if n > 0 {
+			_ = s.f.Truncate(s.size)
+		}
+		return err
+	}
+	s.size += int64(n)
+	return nil
+}
+
+// end 当前文件大小，重放到这里为止
+func (s *spillFile) end() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// read 从已重放的位置开始读取不超过until的完整日志写入buf，返回读到的位置，写入下层writer后调用commit
+// 没有可以重放的日志时ok为false，全部重放完后清空文件并退出溢出状态，只在写日志的协程中调用
+func (s *spillFile) read(buf *bytes.Buffer, until int64) (next int64, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.f == nil {
+		return 0, false
+	}
+	if s.readOff >= s.size {
+		s.reset()
+		return 0, false
+	}
+	if until > s.size {
+		until = s.size
+	}
+	if s.readOff >= until {
+		return 0, false
+	}
+
+	size := until - s.readOff
+	if size > spillReadSize {
+		size = spillReadSize
+	}
+	if int64(cap(s.rbuf)) < size {
+		s.rbuf = make([]byte, size)
+	}
+	data := s.rbuf[:size]
+	if _, err := s.f.ReadAt(data, s.readOff); err != nil {
+		return s.size, true // 读不出来的日志无法重放，丢弃
+	}
+
+	next = s.readOff
+	for len(data) >= spillHeaderSize {
+		l := int64(binary.BigEndian.Uint32(data))
+		if int64(len(data)) < spillHeaderSize+l {
+			break
+		}
+		buf.Write(data[spillHeaderSize : spillHeaderSize+l])
+		data = data[spillHeaderSize+l:]
+		next += spillHeaderSize + l
+	}
+	if next > s.readOff {
+		return next, true
+	}
+
+	// 单条日志超过读取缓冲
+	l := int64(binary.BigEndian.Uint32(data))
+	if s.readOff+spillHeaderSize+l > s.size { // 进程退出时没有写完整的日志
+		return s.size, true
+	}
+	rec := make([]byte, spillHeaderSize+l)
+	if _, err := s.f.ReadAt(rec, s.readOff); err != nil {
+		return s.size, true
+	}
+	buf.Write(rec[spillHeaderSize:])
+	return s.readOff + spillHeaderSize + l, true
+}
+
+// commit 读出的日志已经写入下层writer，保存重放位置，进程重启后从这里继续
+// 保存位置之前进程退出时最多重复重放一次读取的日志
+func (s *spillFile) commit(next int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.readOff = next
+	if s.readOff >= s.size {
+		s.reset()
+		return
+	}
+	s.writeOffset()
+}
+
+// writeOffset 把已重放的位置写到文件开头，调用方持有mu
+func (s *spillFile) writeOffset() {
+	var off [spillOffsetSize]byte
+	binary.BigEndian.PutUint64(off[:], uint64(s.readOff))
+	_, _ = s.f.WriteAt(off[:], 0)
+}
+
+// reset 清空文件，之后的日志重新进入队列，调用方持有mu
+func (s *spillFile) reset() {
+	_ = s.f.Truncate(0)
+	s.size, s.readOff = spillOffsetSize, spillOffsetSize
+	s.writeOffset()
+	atomic.StoreInt32(&s.active, 0)
+}
+
+// close 已经全部重放时删除文件，关闭文件释放锁
+func (s *spillFile) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.f == nil {
+		return nil
+	}
+	if s.readOff >= s.size { // 持有锁时删除，等待锁的writer拿到锁后会发现文件已经被删除
+		_ = os.Remove(s.path)
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
+
+// spillOrQueue 没有未重放的日志时放入队列，队列满或者有未重放的日志时写入溢出文件
+func (w *AsyncRollWriter) spillOrQueue(log *[]byte) error {
+	s := w.spill
+	if atomic.LoadInt32(&s.active) == 0 {
+		select {
+		case w.logChan <- log:
+			return nil
+		default:
+		}
+	}
+
+	s.mu.Lock()
+	if s.f == nil { // 溢出文件被其他writer占用，阻塞等待队列
+		s.mu.Unlock()
+		w.logChan <- log
+		return nil
+	}
+	defer s.mu.Unlock()
+
+	if atomic.LoadInt32(&s.active) == 0 {
+		select {
+		case w.logChan <- log:
+			return nil
+		default:
+			atomic.StoreInt32(&s.active, 1)
+		}
+	}
+
+	if err := s.write(*log); err != nil {
+		return err
+	}
+	putLog(log)
+	atomic.AddUint64(&w.counters.spilled, 1)
+	return nil
+}
+
+// replaySpill 队列中的日志都写完后，把溢出文件中until之前的日志按顺序写入下层writer
+// 写入失败时不保存重放位置，下次定时刷新时重新重放，已经写入一部分时这部分会重复
+func (w *AsyncRollWriter) replaySpill(buffer *bytes.Buffer, flush func() error, until int64) {
+	if w.spill == nil {
+		return
+	}
+	for atomic.LoadInt32(&w.spill.active) == 1 && len(w.logChan) == 0 {
+		if flush() != nil { // 先写完从队列取出的日志
+			return
+		}
+		next, ok := w.spill.read(buffer, until)
+		if !ok {
+			return
+		}
+		if flush() != nil {
+			return
+		}
+		w.spill.commit(next)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd
+// +build !linux,!darwin,!freebsd
+
+package rollwriter
+
+import (
+	"os"
+)
+
+// lockFile 其他系统暂不支持加锁，同一个溢出文件只能由一个writer使用
+func lockFile(f *os.File) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,18 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package rollwriter
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile 非阻塞地加排他锁，已经被其他writer锁定时返回false，关闭文件时释放
+func lockFile(f *os.File) (bool, error) {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,50 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package rollwriter
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSpillLocked(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	path := filepath.Join(dir, "app.log.spill")
+
+	// 热加载时旧的writer还持有溢出文件
+	old, oldW := newBlockedSpillWriter(t, path)
+	for i := 1; i < 10; i++ {
+		fmt.Fprintln(old, i)
+	}
+
+	tw := newTestWriter(false)
+	w := NewAsyncRollWriter(tw, WithOverflowPolicy(OverflowSpill), WithSpillPath(path))
+	if w.spill == nil || w.spill.locked() {
+		t.Fatal("second writer should wait for the spill lock")
+	}
+
+	// 旧的writer重放完后删除文件释放锁，新的writer之后拿到锁
+	close(oldW.gate)
+	if err := old.Close(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(300 * time.Millisecond)
+	if !w.spill.locked() {
+		t.Error("second writer should take the spill lock after the first closed")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := oldW.lines(); !reflect.DeepEqual(got, seq(0, 10)) {
+		t.Errorf("old writer lines %v", got)
+	}
+	if got := tw.lines(); len(got) != 0 {
+		t.Errorf("new writer replayed %v", got)
+	}
+}
@@ -0,0 +1,187 @@
+package rollwriter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// seq 生成from到to(不含)的编号，作为每行日志的内容
+func seq(from, to int) []string {
+	s := make([]string, 0, to-from)
+	for i := from; i < to; i++ {
+		s = append(s, strconv.Itoa(i))
+	}
+	return s
+}
+
+// newBlockedSpillWriter 创建队列长度为1的spill writer，写入第一条日志并等到写日志的协程阻塞在下层writer上
+func newBlockedSpillWriter(t *testing.T, path string, opt ...AsyncOption) (*AsyncRollWriter, *testWriter) {
+	tw := newTestWriter(true)
+	opts := append([]AsyncOption{WithLogQueueSize(1), WithOverflowPolicy(OverflowSpill), WithSpillPath(path)}, opt...)
+	w := NewAsyncRollWriter(tw, opts...)
+	if w.spill == nil {
+		t.Fatal("spill file not opened")
+	}
+	fmt.Fprintln(w, 0)
+	select {
+	case <-tw.entered:
+	case <-time.After(time.Second):
+		t.Fatal("underlying writer not called")
+	}
+	return w, tw
+}
+
+func TestSpillOrder(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	path := filepath.Join(dir, "app.log.spill")
+
+	w, tw := newBlockedSpillWriter(t, path)
+	// 1在队列中，之后的写入溢出文件
+	for i := 1; i < 100; i++ {
+		fmt.Fprintln(w, i)
+	}
+	if n := w.Stats().Spilled; n != 98 {
+		t.Errorf("spilled %d, want 98", n)
+	}
+
+	// 重放完之前的新日志也写入溢出文件，保证顺序
+	close(tw.gate)
+	for i := 100; i < 200; i++ {
+		fmt.Fprintln(w, i)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := tw.lines(); !reflect.DeepEqual(got, seq(0, 200)) {
+		t.Errorf("lines out of order: %v", got)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("spill file should be removed after replay, err %v", err)
+	}
+}
+
+func TestSpillReplayAfterRestart(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	path := filepath.Join(dir, "app.log.spill")
+
+	s, err := openSpill(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.mu.Lock()
+	for _, line := range seq(0, 10) {
+		if err := s.write([]byte(line + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.mu.Unlock()
+
+	// 重放前4条后进程退出，重放位置保存在文件中
+	var buf bytes.Buffer
+	next, ok := s.read(&buf, spillOffsetSize+4*(spillHeaderSize+2))
+	if !ok || buf.String() != "0\n1\n2\n3\n" {
+		t.Fatalf("read %q ok %v", buf.String(), ok)
+	}
+	s.commit(next)
+	if err := s.close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("spill file with pending entries removed: %v", err)
+	}
+
+	tw := newTestWriter(false)
+	w := NewAsyncRollWriter(tw, WithOverflowPolicy(OverflowSpill), WithSpillPath(path))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := tw.lines(); !reflect.DeepEqual(got, seq(4, 10)) {
+		t.Errorf("replayed %v, want %v", got, seq(4, 10))
+	}
+}
+
+func TestSpillMaxSize(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+
+	// 文件头8字节，每条日志4+2字节
+	s, err := openSpill(filepath.Join(dir, "app.log.spill"), spillOffsetSize+2*(spillHeaderSize+2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, want := range []error{nil, nil, errSpillFull} {
+		if err := s.write([]byte(strconv.Itoa(i) + "\n")); err != want {
+			t.Errorf("write %d err %v, want %v", i, err, want)
+		}
+	}
+}
+
+func TestSpillMaxSizeDrop(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+
+	w, tw := newBlockedSpillWriter(t, filepath.Join(dir, "app.log.spill"), WithDropNoticeInterval(-1))
+	w.spill.maxSize = spillOffsetSize + 3*(spillHeaderSize+2)
+	for i := 1; i < 10; i++ {
+		_, err := fmt.Fprintln(w, i)
+		if full := i > 4; full != (err == errSpillFull) {
+			t.Errorf("write %d err %v", i, err)
+		}
+	}
+	if st := w.Stats(); st.Spilled != 3 || st.Dropped != 5 {
+		t.Errorf("stats %+v, want spilled 3 dropped 5", st)
+	}
+
+	close(tw.gate)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := tw.lines(); !reflect.DeepEqual(got, seq(0, 5)) {
+		t.Errorf("lines %v", got)
+	}
+}
+
+func TestSpillReplayWriteFail(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+
+	w, tw := newBlockedSpillWriter(t, filepath.Join(dir, "app.log.spill"),
+		WithWriteRetry(-1, 0), WithErrorHandler(func(error) {}))
+	for i := 1; i < 10; i++ {
+		fmt.Fprintln(w, i)
+	}
+
+	// 下层写入失败时不保存重放位置
+	tw.setErr(errors.New("disk error"))
+	close(tw.gate)
+	time.Sleep(300 * time.Millisecond)
+	w.spill.mu.Lock()
+	readOff := w.spill.readOff
+	w.spill.mu.Unlock()
+	if readOff != spillOffsetSize {
+		t.Errorf("read offset moved to %d after failed replay", readOff)
+	}
+
+	// 恢复后重放全部溢出的日志，0和1在失败时从队列取出，已经丢失
+	tw.setErr(nil)
+	if err := w.Close(); err == nil { // 返回上次Sync之后第一次写入失败的错误
+		t.Error("close should report the failed write")
+	}
+	if got := tw.lines(); !reflect.DeepEqual(got, seq(2, 10)) {
+		t.Errorf("lines %v, want %v", got, seq(2, 10))
+	}
+}
@@ -0,0 +1,49 @@
+//go:build linux
+// +build linux
+
+package rollwriter
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileWatcher 使用inotify监听目录中文件的删除和移动
+type fileWatcher struct {
+	f *os.File
+}
+
+// newFileWatcher 监听dir，有文件被删除或移走时调用onChange
+func newFileWatcher(dir string, onChange func()) (*fileWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_NONBLOCK | syscall.IN_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+
+	mask := uint32(syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF)
+	if _, err = syscall.InotifyAddWatch(fd, dir, mask); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+
+	// 非阻塞的fd交给runtime poller，Close时Read会返回
+	w := &fileWatcher{f: os.NewFile(uintptr(fd), "inotify")}
+	go w.run(onChange)
+	return w, nil
+}
+
+func (w *fileWatcher) run(onChange func()) {
+	buf := make([]byte, 4096)
+	for {
+		// 不关心具体是哪个文件，写入时会比较inode
+		if _, err := w.f.Read(buf); err != nil {
+			return
+		}
+		onChange()
+	}
+}
+
+// Close 停止监听
+func (w *fileWatcher) Close() error {
+	return w.f.Close()
+}
@@ -0,0 +1,45 @@
+//go:build linux
+// +build linux
+
+package rollwriter
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestInotifyReopen(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+
+	// 检查间隔很长，只能通过inotify发现文件被删除
+	path := filepath.Join(dir, "app.log")
+	w, err := NewRollWriter(path, WithReopenInterval(time.Hour), WithInotify(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if w.watcher == nil {
+		t.Skip("inotify not supported")
+	}
+
+	if _, err := w.Write([]byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for atomic.LoadInt32(&w.checkNow) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, err := w.Write([]byte("b")); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != "b" {
+		t.Errorf("reopened file content %q, want b", got)
+	}
+}
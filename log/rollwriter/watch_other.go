@@ -0,0 +1,20 @@
+//go:build !linux
+// +build !linux
+
+package rollwriter
+
+import (
+	"errors"
+)
+
+// fileWatcher 其他系统暂不支持inotify，定期检查当前文件
+type fileWatcher struct{}
+
+func newFileWatcher(dir string, onChange func()) (*fileWatcher, error) {
+	return nil, errors.New("inotify not supported")
+}
+
+// Close 停止监听
+func (w *fileWatcher) Close() error {
+	return nil
+}
@@ -0,0 +1,220 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultReportInterval 没有配置采样周期时，汇报被丢弃日志条数的间隔
+const defaultReportInterval = time.Second
+
+// newLimitCore 按配置给输出端加上采样和限流，没有配置时直接返回原core
+// 结构为 reportCore -> traceSampleCore -> sampler -> rateLimitCore -> core，采样在Check阶段按消息内容判断，
+// 限流需要caller信息，只能在Write阶段判断
+func newLimitCore(core zapcore.Core, c *OutputConfig) zapcore.Core {
+	sampling := c.Sampling != nil && (c.Sampling.Initial > 0 || c.Sampling.Thereafter > 0)
+	rateLimit := c.RateLimit != nil && c.RateLimit.PerSecond > 0
+	if !sampling && !rateLimit {
+		return core
+	}
+
+	stats := &suppressStats{interval: defaultReportInterval, last: time.Now().UnixNano()}
+	limited := core
+	if rateLimit {
+		limited = &rateLimitCore{
+			Core:    core,
+			limiter: newCallerLimiter(c.RateLimit.PerSecond, c.RateLimit.Burst),
+			stats:   stats,
+		}
+	}
+
+	if sampling {
+		tick := time.Duration(c.Sampling.Tick) * time.Millisecond
+		if tick <= 0 {
+			tick = time.Second
+		}
+		thereafter := c.Sampling.Thereafter
+		if thereafter <= 0 { // 超过Initial后全部丢弃
+			thereafter = int(^uint(0) >> 1)
+		}
+		stats.interval = tick
+		sampler := zapcore.NewSamplerWithOptions(limited, tick, c.Sampling.Initial, thereafter,
+			zapcore.SamplerHook(stats.hook))
+		// zap的采样只处理debug及以上级别，trace按debug的采样参数单独计数
+		limited = &traceSampleCore{
+			Core: sampler,
+			decide: zapcore.NewSamplerWithOptions(sampledCore{}, tick, c.Sampling.Initial, thereafter,
+				zapcore.SamplerHook(stats.hook)),
+		}
+	}
+
+	return &reportCore{Core: limited, out: core, stats: stats}
+}
+
+// suppressStats 统计采样和限流丢弃的日志条数
+type suppressStats struct {
+	interval time.Duration
+	dropped  int64
+	last     int64 // 上次汇报时间 UnixNano
+}
+
+func (s *suppressStats) hook(_ zapcore.Entry, dec zapcore.SamplingDecision) {
+	if dec&zapcore.LogDropped > 0 {
+		atomic.AddInt64(&s.dropped, 1)
+	}
+}
+
+// report 每个周期最多汇报一次，期间有日志被丢弃时输出一条汇总日志
+// 汇总日志直接写入输出端，不受采样、限流和日志级别影响
+func (s *suppressStats) report(out zapcore.Core) {
+	now := time.Now()
+	last := atomic.LoadInt64(&s.last)
+	if now.UnixNano()-last < int64(s.interval) {
+		return
+	}
+	if !atomic.CompareAndSwapInt64(&s.last, last, now.UnixNano()) {
+		return
+	}
+
+	n := atomic.SwapInt64(&s.dropped, 0)
+	if n == 0 {
+		return
+	}
+	_ = out.Write(zapcore.Entry{
+		Level:   zapcore.WarnLevel,
+		Time:    now,
+		Message: fmt.Sprintf("log sampling/rate limit suppressed %d entries in last %s", n, time.Duration(now.UnixNano()-last)),
+	}, nil)
+}
+
+// reportCore 在每次打日志时检查是否需要输出丢弃条数的汇总
+type reportCore struct {
+	zapcore.Core
+	out   zapcore.Core
+	stats *suppressStats
+}
+
+// With 附加fields，汇总日志不需要业务fields
+func (c *reportCore) With(fields []zapcore.Field) zapcore.Core {
+	return &reportCore{Core: c.Core.With(fields), out: c.out, stats: c.stats}
+}
+
+// Check 先汇报再交给采样判断
+func (c *reportCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	c.stats.report(c.out)
+	return c.Core.Check(ent, ce)
+}
+
+// traceSampleCore trace级别低于zap采样的最低级别，会直接跳过采样，这里先按debug级别判断是否采样
+type traceSampleCore struct {
+	zapcore.Core
+	decide zapcore.Core // 只用于判断trace日志是否采样，和Core使用相同的采样参数
+}
+
+// With 附加fields，采样计数共享
+func (c *traceSampleCore) With(fields []zapcore.Field) zapcore.Core {
+	return &traceSampleCore{Core: c.Core.With(fields), decide: c.decide}
+}
+
+// Check trace日志采样通过后再交给Core，Core中的采样不处理trace
+func (c *traceSampleCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level == zapTraceLevel && c.Enabled(ent.Level) {
+		debug := ent
+		debug.Level = zapcore.DebugLevel
+		if c.decide.Check(debug, nil) == nil {
+			return ce
+		}
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// sampledEntry sampledCore.Check的返回值，不为nil表示采样通过
+var sampledEntry = &zapcore.CheckedEntry{}
+
+// sampledCore 放在采样器下层，只用于获取采样结果，不写入
+type sampledCore struct{}
+
+func (sampledCore) Enabled(zapcore.Level) bool          { return true }
+func (c sampledCore) With([]zapcore.Field) zapcore.Core { return c }
+func (sampledCore) Check(zapcore.Entry, *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return sampledEntry
+}
+func (sampledCore) Write(zapcore.Entry, []zapcore.Field) error { return nil }
+func (sampledCore) Sync() error                                { return nil }
+
+// rateLimitCore 按caller限流
+type rateLimitCore struct {
+	zapcore.Core
+	limiter *callerLimiter
+	stats   *suppressStats
+}
+
+// With 附加fields，限流状态共享
+func (c *rateLimitCore) With(fields []zapcore.Field) zapcore.Core {
+	return &rateLimitCore{Core: c.Core.With(fields), limiter: c.limiter, stats: c.stats}
+}
+
+// Check 需要在Write阶段才能拿到caller，这里把自己加入
+func (c *rateLimitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write 超过限流的日志直接丢弃
+func (c *rateLimitCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if !c.limiter.allow(ent.Caller.String(), ent.Time) {
+		atomic.AddInt64(&c.stats.dropped, 1)
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// callerLimiter 每个caller一个令牌桶
+type callerLimiter struct {
+	rate    float64
+	burst   float64
+	buckets sync.Map // caller -> *tokenBucket
+}
+
+type tokenBucket struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newCallerLimiter(perSecond, burst int) *callerLimiter {
+	if burst <= 0 {
+		burst = perSecond
+	}
+	return &callerLimiter{rate: float64(perSecond), burst: float64(burst)}
+}
+
+func (l *callerLimiter) allow(caller string, now time.Time) bool {
+	v, ok := l.buckets.Load(caller)
+	if !ok {
+		v, _ = l.buckets.LoadOrStore(caller, &tokenBucket{tokens: l.burst, last: now})
+	}
+	b := v.(*tokenBucket)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * l.rate
+		if b.tokens > l.burst {
+			b.tokens = l.burst
+		}
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
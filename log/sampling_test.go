@@ -0,0 +1,99 @@
+package log
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func newLimitLogger(t *testing.T, sampling *SamplingConfig, rateLimit *RateLimitConfig) (Logger, *testBuffer) {
+	c := testOutputConfig(t.Name(), "trace")
+	c.Sampling = sampling
+	c.RateLimit = rateLimit
+	// 直接调用logger的方法，caller少一层，限流按caller区分
+	l := NewZapLogWithCallerSkip(Config{c}, 1)
+	return l, getTestBuffer(t.Name())
+}
+
+// count 统计内容为msg的日志条数
+func count(msgs []string, msg string) int {
+	n := 0
+	for _, m := range msgs {
+		if m == msg {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSampling(t *testing.T) {
+	tests := []struct {
+		name     string
+		sampling SamplingConfig
+		want     int // 10条相同日志输出的条数
+	}{
+		{"initial and thereafter", SamplingConfig{Initial: 2, Thereafter: 3}, 4},
+		{"initial only", SamplingConfig{Initial: 3}, 3},
+		{"thereafter only", SamplingConfig{Thereafter: 5}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sampling := tt.sampling
+			l, buf := newLimitLogger(t, &sampling, nil)
+			for i := 0; i < 10; i++ {
+				l.Trace("trace")
+				l.Debug("debug")
+				l.Info("info")
+			}
+			msgs := buf.messages(t)
+			for _, msg := range []string{"trace", "debug", "info"} {
+				if n := count(msgs, msg); n != tt.want {
+					t.Errorf("%s got %d entries, want %d", msg, n, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	l, buf := newLimitLogger(t, nil, &RateLimitConfig{PerSecond: 1, Burst: 2})
+	for i := 0; i < 5; i++ {
+		l.Info("first")
+	}
+	for i := 0; i < 5; i++ {
+		l.Info("second") // 不同的代码位置分别限流
+	}
+	msgs := buf.messages(t)
+	if n := count(msgs, "first"); n != 2 {
+		t.Errorf("first callsite got %d entries, want 2", n)
+	}
+	if n := count(msgs, "second"); n != 2 {
+		t.Errorf("second callsite got %d entries, want 2", n)
+	}
+}
+
+func TestSuppressReport(t *testing.T) {
+	l, buf := newLimitLogger(t, &SamplingConfig{Initial: 1, Tick: 50}, nil)
+	l.SetLevel("0", LevelError) // 汇总日志不受日志级别影响
+	for i := 0; i < 5; i++ {
+		l.Error("same")
+	}
+	time.Sleep(60 * time.Millisecond)
+	l.Error("same")
+
+	entries := buf.entries(t)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3: %v", len(entries), entries)
+	}
+	report := entries[1]
+	if report["L"] != "WARN" || !strings.Contains(report["M"].(string), "suppressed 4 entries") {
+		t.Errorf("report entry got %v", report)
+	}
+
+	// 没有丢弃时不汇报
+	time.Sleep(60 * time.Millisecond)
+	l.Error("other")
+	if n := len(buf.entries(t)); n != 4 {
+		t.Errorf("got %d entries after idle tick, want 4", n)
+	}
+}
@@ -0,0 +1,78 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/hust-tianbo/go_lib/log/syslogwriter"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// levelToSeverity 日志级别对应的syslog severity
+var levelToSeverity = map[zapcore.Level]syslogwriter.Severity{
+	zapTraceLevel:       syslogwriter.SeverityDebug,
+	zapcore.DebugLevel:  syslogwriter.SeverityDebug,
+	zapcore.InfoLevel:   syslogwriter.SeverityInfo,
+	zapcore.WarnLevel:   syslogwriter.SeverityWarning,
+	zapcore.ErrorLevel:  syslogwriter.SeverityErr,
+	zapcore.DPanicLevel: syslogwriter.SeverityCrit,
+	zapcore.PanicLevel:  syslogwriter.SeverityCrit,
+	zapcore.FatalLevel:  syslogwriter.SeverityCrit,
+}
+
+// syslogOutput 将syslogwriter.Writer适配成levelWriter
+type syslogOutput struct {
+	w *syslogwriter.Writer
+}
+
+// WriteLevel 按级别对应的severity写入
+func (o *syslogOutput) WriteLevel(level zapcore.Level, p []byte) (int, error) {
+	sev, ok := levelToSeverity[level]
+	if !ok {
+		sev = syslogwriter.SeverityInfo
+	}
+	return o.w.WriteSeverity(sev, p)
+}
+
+// Sync 每条日志直接发送，不需要刷盘
+func (o *syslogOutput) Sync() error {
+	return nil
+}
+
+func newSyslogCore(c *OutputConfig) (zapcore.Core, zap.AtomicLevel, io.Closer, error) {
+	sc := c.WriteConfig.Syslog
+
+	opts := []syslogwriter.Option{
+		syslogwriter.WithNetwork(sc.Network, sc.Address),
+		syslogwriter.WithAppName(sc.AppName),
+	}
+	if sc.Facility != "" {
+		facility, ok := syslogwriter.FacilityNames[sc.Facility]
+		if !ok {
+			return nil, zap.AtomicLevel{}, nil, fmt.Errorf("syslog facility %s invalid", sc.Facility)
+		}
+		opts = append(opts, syslogwriter.WithFacility(facility))
+	}
+	if sc.Format != "" {
+		opts = append(opts, syslogwriter.WithFormat(sc.Format))
+	}
+	if sc.Framing != "" {
+		opts = append(opts, syslogwriter.WithFraming(sc.Framing))
+	}
+	if sc.WriteTimeout != 0 {
+		opts = append(opts, syslogwriter.WithWriteTimeout(time.Duration(sc.WriteTimeout)*time.Millisecond))
+	}
+
+	w, err := syslogwriter.New(opts...)
+	if err != nil {
+		return nil, zap.AtomicLevel{}, nil, err
+	}
+
+	// 日志级别
+	lvl := zap.NewAtomicLevelAt(Levels[c.Level])
+
+	return newLevelCore(newEncoder(c), &syslogOutput{w: w}, lvl), lvl, w, nil
+}
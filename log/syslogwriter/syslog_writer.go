@@ -0,0 +1,377 @@
+package syslogwriter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var _ io.WriteCloser = (*Writer)(nil)
+
+// Severity syslog日志严重程度
+type Severity int
+
+// syslog severity const
+const (
+	SeverityEmerg Severity = iota
+	SeverityAlert
+	SeverityCrit
+	SeverityErr
+	SeverityWarning
+	SeverityNotice
+	SeverityInfo
+	SeverityDebug
+)
+
+// Facility syslog日志来源
+type Facility int
+
+// syslog facility const
+const (
+	FacilityKern Facility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLpr
+	FacilityNews
+	FacilityUucp
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFtp
+	_
+	_
+	_
+	_
+	FacilityLocal0
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// FacilityNames facility名称
+var FacilityNames = map[string]Facility{
+	"kern":     FacilityKern,
+	"user":     FacilityUser,
+	"mail":     FacilityMail,
+	"daemon":   FacilityDaemon,
+	"auth":     FacilityAuth,
+	"syslog":   FacilitySyslog,
+	"lpr":      FacilityLpr,
+	"news":     FacilityNews,
+	"uucp":     FacilityUucp,
+	"cron":     FacilityCron,
+	"authpriv": FacilityAuthPriv,
+	"ftp":      FacilityFtp,
+	"local0":   FacilityLocal0,
+	"local1":   FacilityLocal1,
+	"local2":   FacilityLocal2,
+	"local3":   FacilityLocal3,
+	"local4":   FacilityLocal4,
+	"local5":   FacilityLocal5,
+	"local6":   FacilityLocal6,
+	"local7":   FacilityLocal7,
+}
+
+// 消息格式
+const (
+	// FormatRFC5424 <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD MSG
+	FormatRFC5424 = "rfc5424"
+	// FormatRFC3164 <PRI>TIMESTAMP HOSTNAME TAG[PID]: MSG
+	FormatRFC3164 = "rfc3164"
+)
+
+// 流式连接(tcp, unix)的分帧方式，见RFC 6587
+const (
+	// FramingOctetCounting 消息前加上长度和空格
+	FramingOctetCounting = "octet_counting"
+	// FramingNonTransparent 消息后加换行符
+	FramingNonTransparent = "non_transparent"
+)
+
+// 本机syslog默认地址
+var localAddresses = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+type Options struct {
+	Network           string        // udp tcp unix unixgram，为空时连接本机syslog
+	Address           string        // 地址，unix socket为文件路径
+	Facility          Facility      // 日志来源
+	AppName           string        // 应用名，默认为进程名
+	Hostname          string        // 主机名，默认为os.Hostname
+	Format            string        // 消息格式 rfc5424 rfc3164
+	Framing           string        // 流式连接的分帧方式
+	DialTimeout       time.Duration // 连接超时时间
+	ReconnectInterval time.Duration // 连接失败后的重连间隔，期间的日志直接返回错误
+	WriteTimeout      time.Duration // 单条日志的写入超时时间，避免syslog服务端阻塞时卡住写日志的协程，小于等于0时不设置
+}
+
+type Option func(*Options)
+
+func WithNetwork(network, address string) Option {
+	return func(opt *Options) {
+		opt.Network = network
+		opt.Address = address
+	}
+}
+
+func WithFacility(f Facility) Option {
+	return func(opt *Options) {
+		opt.Facility = f
+	}
+}
+
+func WithAppName(name string) Option {
+	return func(opt *Options) {
+		opt.AppName = name
+	}
+}
+
+func WithHostname(name string) Option {
+	return func(opt *Options) {
+		opt.Hostname = name
+	}
+}
+
+func WithFormat(format string) Option {
+	return func(opt *Options) {
+		opt.Format = format
+	}
+}
+
+func WithFraming(framing string) Option {
+	return func(opt *Options) {
+		opt.Framing = framing
+	}
+}
+
+func WithReconnectInterval(d time.Duration) Option {
+	return func(opt *Options) {
+		opt.ReconnectInterval = d
+	}
+}
+
+func WithWriteTimeout(d time.Duration) Option {
+	return func(opt *Options) {
+		opt.WriteTimeout = d
+	}
+}
+
+// Writer 写syslog的writer，连接断开后自动重连
+type Writer struct {
+	opts *Options
+	pid  int
+
+	mu       sync.Mutex
+	conn     net.Conn
+	stream   bool      // 当前连接是否为流式连接
+	dialFail time.Time // 上次连接失败的时间
+	buf      bytes.Buffer
+}
+
+// New 创建syslog writer，创建时会尝试连接，连接失败时在写日志时重连
+func New(opt ...Option) (*Writer, error) {
+	opts := &Options{
+		Facility:          FacilityUser,
+		Format:            FormatRFC5424,
+		Framing:           FramingOctetCounting,
+		DialTimeout:       time.Second,
+		ReconnectInterval: time.Second,
+		WriteTimeout:      time.Second,
+	}
+
+	for _, o := range opt {
+		o(opts)
+	}
+
+	switch opts.Format {
+	case FormatRFC5424, FormatRFC3164:
+	default:
+		return nil, fmt.Errorf("syslog format %s invalid", opts.Format)
+	}
+	switch opts.Framing {
+	case FramingOctetCounting, FramingNonTransparent:
+	default:
+		return nil, fmt.Errorf("syslog framing %s invalid", opts.Framing)
+	}
+	if opts.Network != "" && opts.Address == "" {
+		return nil, errors.New("syslog address empty")
+	}
+	if opts.AppName == "" {
+		opts.AppName = filepath.Base(os.Args[0])
+	}
+	if opts.Hostname == "" {
+		opts.Hostname, _ = os.Hostname()
+	}
+
+	w := &Writer{
+		opts: opts,
+		pid:  os.Getpid(),
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_ = w.connect()
+
+	return w, nil
+}
+
+// Write 以info级别写入
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.WriteSeverity(SeverityInfo, p)
+}
+
+// WriteSeverity 以指定级别写入一条日志，写入失败时重连后重试一次
+func (w *Writer) WriteSeverity(sev Severity, p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		if err := w.connect(); err != nil {
+			return 0, err
+		}
+	}
+
+	msg := w.format(sev, p)
+	err := w.write(msg)
+	if err == nil {
+		return len(p), nil
+	}
+
+	// 连接可能已经断开，重连后重试一次，写入超时说明服务端阻塞，不再重试
+	w.conn.Close()
+	w.conn = nil
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return 0, err
+	}
+	if err := w.connect(); err != nil {
+		return 0, err
+	}
+	msg = w.format(sev, p)
+	if err := w.write(msg); err != nil {
+		w.conn.Close()
+		w.conn = nil
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// write 设置超时后写入当前连接，调用方持有锁
+func (w *Writer) write(msg []byte) error {
+	if w.opts.WriteTimeout > 0 {
+		if err := w.conn.SetWriteDeadline(time.Now().Add(w.opts.WriteTimeout)); err != nil {
+			return err
+		}
+	}
+	_, err := w.conn.Write(msg)
+	return err
+}
+
+// Close 关闭连接
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// connect 建立连接，调用方持有锁
+func (w *Writer) connect() error {
+	if !w.dialFail.IsZero() && time.Since(w.dialFail) < w.opts.ReconnectInterval {
+		return errors.New("syslog not connected, wait for reconnect")
+	}
+
+	conn, stream, err := w.dial()
+	if err != nil {
+		w.dialFail = time.Now()
+		return err
+	}
+
+	w.dialFail = time.Time{}
+	w.conn = conn
+	w.stream = stream
+	return nil
+}
+
+func (w *Writer) dial() (net.Conn, bool, error) {
+	if w.opts.Network != "" {
+		conn, err := net.DialTimeout(w.opts.Network, w.opts.Address, w.opts.DialTimeout)
+		if err != nil {
+			return nil, false, err
+		}
+		return conn, isStream(w.opts.Network), nil
+	}
+
+	// 没有配置时连接本机syslog
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, addr := range localAddresses {
+			conn, err := net.DialTimeout(network, addr, w.opts.DialTimeout)
+			if err == nil {
+				return conn, isStream(network), nil
+			}
+		}
+	}
+	return nil, false, errors.New("local syslog server not found")
+}
+
+func isStream(network string) bool {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	}
+	return false
+}
+
+// format 按配置的格式生成消息，返回的数据在下次调用前有效
+func (w *Writer) format(sev Severity, p []byte) []byte {
+	p = bytes.TrimRight(p, "\r\n")
+	pri := int(w.opts.Facility)*8 + int(sev)
+	now := time.Now()
+
+	var msg bytes.Buffer
+	if w.opts.Format == FormatRFC3164 {
+		fmt.Fprintf(&msg, "<%d>%s %s %s[%d]: ", pri, now.Format(time.Stamp),
+			nilValue(w.opts.Hostname), w.opts.AppName, w.pid)
+	} else {
+		fmt.Fprintf(&msg, "<%d>1 %s %s %s %d - - ", pri, now.Format("2006-01-02T15:04:05.000000Z07:00"),
+			nilValue(w.opts.Hostname), nilValue(w.opts.AppName), w.pid)
+	}
+	msg.Write(p)
+
+	w.buf.Reset()
+	switch {
+	case !w.stream:
+		w.buf.Write(msg.Bytes())
+	case w.opts.Framing == FramingOctetCounting:
+		w.buf.WriteString(strconv.Itoa(msg.Len()))
+		w.buf.WriteByte(' ')
+		w.buf.Write(msg.Bytes())
+	default:
+		w.buf.Write(msg.Bytes())
+		w.buf.WriteByte('\n')
+	}
+	return w.buf.Bytes()
+}
+
+func nilValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
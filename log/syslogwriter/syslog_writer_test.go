@@ -0,0 +1,253 @@
+package syslogwriter
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listenUnixgram 在临时目录创建unixgram监听，返回socket路径
+func listenUnixgram(t *testing.T, dir string) (*net.UnixConn, string) {
+	path := filepath.Join(dir, "syslog.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("listen unixgram fail:%v", err)
+	}
+	return conn, path
+}
+
+// readPacket 读取一个数据报
+func readPacket(t *testing.T, conn net.PacketConn) string {
+	buf := make([]byte, 4096)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read packet fail:%v", err)
+	}
+	return string(buf[:n])
+}
+
+func TestWriteUDPRFC5424(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen udp fail:%v", err)
+	}
+	defer conn.Close()
+
+	w, err := New(WithNetwork("udp", conn.LocalAddr().String()), WithFacility(FacilityLocal0),
+		WithAppName("app"), WithHostname("host"))
+	if err != nil {
+		t.Fatalf("new writer fail:%v", err)
+	}
+	defer w.Close()
+
+	if _, err := w.WriteSeverity(SeverityErr, []byte("hello world\n")); err != nil {
+		t.Fatalf("write fail:%v", err)
+	}
+
+	// 数据报不分帧，结尾的换行符被去掉
+	got := readPacket(t, conn)
+	re := regexp.MustCompile(`^<131>1 \d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{6}\S+ host app ` +
+		strconv.Itoa(os.Getpid()) + ` - - hello world$`)
+	if !re.MatchString(got) {
+		t.Errorf("rfc5424 message %q not match", got)
+	}
+}
+
+func TestWriteUnixgramRFC3164(t *testing.T) {
+	dir, err := ioutil.TempDir("", "syslog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	conn, path := listenUnixgram(t, dir)
+	defer conn.Close()
+
+	w, err := New(WithNetwork("unixgram", path), WithFormat(FormatRFC3164), WithAppName("app"),
+		WithHostname("host"))
+	if err != nil {
+		t.Fatalf("new writer fail:%v", err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("hello")); err != nil {
+		t.Fatalf("write fail:%v", err)
+	}
+
+	got := readPacket(t, conn)
+	re := regexp.MustCompile(`^<14>[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2} host app\[` +
+		strconv.Itoa(os.Getpid()) + `\]: hello$`)
+	if !re.MatchString(got) {
+		t.Errorf("rfc3164 message %q not match", got)
+	}
+}
+
+func TestPriority(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen udp fail:%v", err)
+	}
+	defer conn.Close()
+
+	tests := []struct {
+		facility Facility
+		severity Severity
+		pri      string
+	}{
+		{FacilityKern, SeverityEmerg, "<0>"},
+		{FacilityUser, SeverityDebug, "<15>"},
+		{FacilityUser, SeverityWarning, "<12>"},
+		{FacilityDaemon, SeverityInfo, "<30>"},
+		{FacilityLocal0, SeverityCrit, "<130>"},
+		{FacilityLocal7, SeverityDebug, "<191>"},
+	}
+	for _, tt := range tests {
+		w, err := New(WithNetwork("udp", conn.LocalAddr().String()), WithFacility(tt.facility))
+		if err != nil {
+			t.Fatalf("new writer fail:%v", err)
+		}
+		if _, err := w.WriteSeverity(tt.severity, []byte("msg")); err != nil {
+			t.Fatalf("write fail:%v", err)
+		}
+		w.Close()
+
+		if got := readPacket(t, conn); !strings.HasPrefix(got, tt.pri) {
+			t.Errorf("facility %d severity %d got %q, want prefix %s", tt.facility, tt.severity, got, tt.pri)
+		}
+	}
+}
+
+func TestStreamFraming(t *testing.T) {
+	tests := []struct {
+		framing string
+		want    func(msg string) string
+	}{
+		{FramingOctetCounting, func(msg string) string { return strconv.Itoa(len(msg)) + " " + msg }},
+		{FramingNonTransparent, func(msg string) string { return msg + "\n" }},
+	}
+	for _, tt := range tests {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen tcp fail:%v", err)
+		}
+
+		w, err := New(WithNetwork("tcp", ln.Addr().String()), WithFraming(tt.framing), WithFormat(FormatRFC3164),
+			WithAppName("app"), WithHostname("host"))
+		if err != nil {
+			t.Fatalf("new writer fail:%v", err)
+		}
+		conn, err := ln.Accept()
+		if err != nil {
+			t.Fatalf("accept fail:%v", err)
+		}
+		if _, err := w.Write([]byte("hello\n")); err != nil {
+			t.Fatalf("write fail:%v", err)
+		}
+		w.Close()
+
+		data, _ := ioutil.ReadAll(bufio.NewReader(conn))
+		conn.Close()
+		ln.Close()
+
+		got := string(data)
+		msg := strings.TrimRight(got[strings.Index(got, "<"):], "\n")
+		if want := tt.want(msg); got != want {
+			t.Errorf("framing %s got %q, want %q", tt.framing, got, want)
+		}
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	dir, err := ioutil.TempDir("", "syslog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	conn, path := listenUnixgram(t, dir)
+	w, err := New(WithNetwork("unixgram", path), WithReconnectInterval(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("new writer fail:%v", err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("before")); err != nil {
+		t.Fatalf("write fail:%v", err)
+	}
+	readPacket(t, conn)
+
+	// syslog重启，socket文件被删除后重新创建
+	conn.Close()
+	os.Remove(path)
+	if _, err := w.Write([]byte("down")); err == nil {
+		t.Fatal("write to closed socket should fail")
+	}
+	if _, err := w.Write([]byte("wait")); err == nil {
+		t.Fatal("write before reconnect interval should fail")
+	}
+
+	conn, path = listenUnixgram(t, dir)
+	defer conn.Close()
+	time.Sleep(60 * time.Millisecond)
+
+	if _, err := w.Write([]byte("after")); err != nil {
+		t.Fatalf("write after reconnect fail:%v", err)
+	}
+	if got := readPacket(t, conn); !strings.HasSuffix(got, " after") {
+		t.Errorf("got %q after reconnect", got)
+	}
+}
+
+func TestWriteTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen tcp fail:%v", err)
+	}
+	defer ln.Close()
+
+	w, err := New(WithNetwork("tcp", ln.Addr().String()), WithWriteTimeout(50*time.Millisecond),
+		WithReconnectInterval(time.Minute))
+	if err != nil {
+		t.Fatalf("new writer fail:%v", err)
+	}
+	defer w.Close()
+
+	// 服务端不读取数据，写满缓冲区后写入超时返回错误
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatalf("accept fail:%v", err)
+	}
+	defer conn.Close()
+
+	msg := bytes.Repeat([]byte("x"), 64*1024)
+	start := time.Now()
+	for time.Since(start) < 10*time.Second {
+		if _, err = w.Write(msg); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		t.Fatal("write to stalled server should time out")
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	if _, err := New(WithFormat("rfc0")); err == nil {
+		t.Error("invalid format should fail")
+	}
+	if _, err := New(WithFraming("none")); err == nil {
+		t.Error("invalid framing should fail")
+	}
+	if _, err := New(WithNetwork("udp", "")); err == nil {
+		t.Error("empty address should fail")
+	}
+}
@@ -0,0 +1,49 @@
+package log
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// OutputError 输出端最近一次写入失败的信息
+type OutputError struct {
+	Name   string    // 输出端名称，没有配置时为输出端下标
+	Writer string    // 输出端类型
+	At     time.Time // 最近一次写入失败的时间
+	Err    error     // 最近一次写入失败的错误，没有失败过时为nil
+}
+
+// ErrorReporter 可以获取输出端写入错误的Logger，NewZapLog创建的Logger都实现了该接口
+type ErrorReporter interface {
+	// Errors 获取异步写入的输出端最近一次写入失败的时间和错误，可用于健康检查
+	Errors() []OutputError
+}
+
+// Errors 获取已注册的logger中异步写入的输出端最近一次写入失败的信息，logger不存在或者不支持时返回nil
+func Errors(name string) []OutputError {
+	if r, ok := Get(name).(ErrorReporter); ok {
+		return r.Errors()
+	}
+	return nil
+}
+
+// lastErrorWriter 可以获取最近一次写入错误的writer，如rollwriter.AsyncRollWriter
+type lastErrorWriter interface {
+	LastError() (at time.Time, err error)
+}
+
+// WriteErrorHandler 异步写入文件失败(重试后仍然失败)时的回调，output为输出端名称
+type WriteErrorHandler func(output string, err error)
+
+var writeErrorHandler atomic.Value
+
+// SetWriteErrorHandler 设置异步写入文件失败时的回调，对之后创建或者热加载的输出端生效
+// 不设置时限频输出到stderr
+func SetWriteErrorHandler(h WriteErrorHandler) {
+	writeErrorHandler.Store(h)
+}
+
+func getWriteErrorHandler() WriteErrorHandler {
+	h, _ := writeErrorHandler.Load().(WriteErrorHandler)
+	return h
+}
@@ -0,0 +1,66 @@
+package log
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestWriteErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log_errors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var mu sync.Mutex
+	handled := make(map[string]error)
+	old := getWriteErrorHandler()
+	SetWriteErrorHandler(func(output string, err error) {
+		mu.Lock()
+		handled[output] = err
+		mu.Unlock()
+	})
+	defer SetWriteErrorHandler(old)
+
+	// 日志路径是目录，打开文件失败
+	path := filepath.Join(dir, "app.log")
+	if err := os.Mkdir(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	name := t.Name()
+	l := NewZapLog(Config{{Name: "file", Writer: OutputFile, Level: "debug",
+		WriteConfig: WriteConfig{Filename: path, WriteMode: WriteAsync, WriteRetries: -1}}})
+	Register(name, l)
+	defer closeAll(l.(*zapLog).holder.load().closers)
+
+	errs := Errors(name)
+	if len(errs) != 1 || errs[0].Err != nil || !errs[0].At.IsZero() {
+		t.Fatalf("errors before write %+v", errs)
+	}
+
+	l.Info("fail")
+	if err := l.Sync(); err == nil {
+		t.Error("sync should return the write error")
+	}
+
+	errs = Errors(name)
+	if len(errs) != 1 {
+		t.Fatalf("got errors %+v", errs)
+	}
+	e := errs[0]
+	if _, ok := e.Err.(*os.PathError); !ok || e.Name != "file" || e.Writer != OutputFile || e.At.IsZero() {
+		t.Errorf("error %+v, want the open error", e)
+	}
+	mu.Lock()
+	if handled["file"] != e.Err {
+		t.Errorf("handler got %v, want %v", handled["file"], e.Err)
+	}
+	mu.Unlock()
+
+	if Errors("unknown") != nil {
+		t.Error("errors of unknown logger should be nil")
+	}
+}
@@ -1,6 +1,7 @@
 package log
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -21,9 +22,13 @@ var defaultConfig = []OutputConfig{
 	},
 }
 
+// zapTraceLevel trace级别，比zapcore.DebugLevel低一级，zap本身没有trace级别
+const zapTraceLevel = zapcore.DebugLevel - 1
+
 // Levels zapcore level
 var Levels = map[string]zapcore.Level{
 	"":      zapcore.DebugLevel,
+	"trace": zapTraceLevel,
 	"debug": zapcore.DebugLevel,
 	"info":  zapcore.InfoLevel,
 	"warn":  zapcore.WarnLevel,
@@ -32,7 +37,7 @@ var Levels = map[string]zapcore.Level{
 }
 
 var levelToZapLevel = map[Level]zapcore.Level{
-	LevelTrace: zapcore.DebugLevel,
+	LevelTrace: zapTraceLevel,
 	LevelDebug: zapcore.DebugLevel,
 	LevelInfo:  zapcore.InfoLevel,
 	LevelWarn:  zapcore.WarnLevel,
@@ -41,6 +46,7 @@ var levelToZapLevel = map[Level]zapcore.Level{
 }
 
 var zapLevelToLevel = map[zapcore.Level]Level{
+	zapTraceLevel:      LevelTrace,
 	zapcore.DebugLevel: LevelDebug,
 	zapcore.InfoLevel:  LevelInfo,
 	zapcore.WarnLevel:  LevelWarn,
@@ -56,28 +62,17 @@ func NewZapLog(c Config) Logger {
 // NewZapLogWithCallerSkip 创建一个zap默认实现的logger
 func NewZapLogWithCallerSkip(c Config, callerSkip int) Logger {
 
-	cores := make([]zapcore.Core, 0, len(c))
-	levels := make([]zap.AtomicLevel, 0, len(c))
-	for _, o := range c {
-		writer, ok := writers[o.Writer]
-		if !ok {
-			fmt.Printf("log writer core:%s no registered!\n", o.Writer)
-			return nil
-		}
-
-		decoder := &Decoder{OutputConfig: &o}
-		err := writer.Setup(o.Writer, decoder)
-		if err != nil {
-			fmt.Printf("log writer setup core:%s fail:%v!\n", o.Writer, err)
-			return nil
-		}
-
-		cores = append(cores, decoder.Core)
-		levels = append(levels, decoder.ZapLevel)
+	state, err := newCoreState(c, nil)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return nil
 	}
 
+	holder := &coreHolder{}
+	holder.store(state)
+
 	logger := zap.New(
-		zapcore.NewTee(cores...),
+		&swapCore{holder: holder},
 		zap.AddCallerSkip(callerSkip),
 		zap.AddCaller(),
 	)
@@ -86,11 +81,65 @@ func NewZapLogWithCallerSkip(c Config, callerSkip int) Logger {
 	// zap.RedirectStdLog(logger)
 
 	return &zapLog{
-		levels: levels,
+		holder: holder,
 		logger: logger,
 	}
 }
 
+// newCoreState 根据配置创建所有输出端的core，热加载时old中配置没有变化的输出端直接复用
+func newCoreState(c Config, old *coreState) (*coreState, error) {
+	state := &coreState{
+		outputs: make([]zapOutput, 0, len(c)),
+	}
+
+	cores := make([]zapcore.Core, 0, len(c))
+	created := make([]io.Closer, 0, len(c)) // 出错时只关闭新创建的输出端
+	used := make(map[int]bool)
+	for i, o := range c {
+		if o.Name == "" {
+			o.Name = strconv.Itoa(i)
+		}
+
+		out, ok := old.reuse(&o, used)
+		if !ok {
+			writer, ok := writers[o.Writer]
+			if !ok {
+				closeAll(created)
+				return nil, fmt.Errorf("log writer core:%s no registered!", o.Writer)
+			}
+
+			conf := o // Setup会补全配置，保存原始配置用于比较
+			decoder := &Decoder{OutputConfig: &o}
+			err := writer.Setup(o.Writer, decoder)
+			if err != nil {
+				closeAll(created)
+				return nil, fmt.Errorf("log writer setup core:%s fail:%v!", o.Writer, err)
+			}
+			if decoder.Closer != nil {
+				created = append(created, decoder.Closer)
+			}
+
+			out = zapOutput{
+				name:   o.Name,
+				writer: o.Writer,
+				level:  decoder.ZapLevel,
+				closer: decoder.Closer,
+				core:   newLimitCore(decoder.Core, &o),
+				conf:   conf,
+			}
+		}
+
+		cores = append(cores, out.core)
+		state.outputs = append(state.outputs, out)
+		if out.closer != nil {
+			state.closers = append(state.closers, out.closer)
+		}
+	}
+
+	state.core = zapcore.NewTee(cores...)
+	return state, nil
+}
+
 func newConsoleCore(c *OutputConfig) (zapcore.Core, zap.AtomicLevel) {
 	lvl := zap.NewAtomicLevelAt(Levels[c.Level])
 	return zapcore.NewCore(
@@ -99,52 +148,90 @@ func newConsoleCore(c *OutputConfig) (zapcore.Core, zap.AtomicLevel) {
 		lvl), lvl
 }
 
-func newFileCore(c *OutputConfig) (zapcore.Core, zap.AtomicLevel) {
+func newFileCore(c *OutputConfig) (zapcore.Core, zap.AtomicLevel, io.Closer, error) {
 	var ws zapcore.WriteSyncer
-	var writer io.Writer
-	var writeErr error
 
 	fmt.Printf("[newFileCore]%+v,%+v", c.WriteConfig.RollType, c.WriteConfig.WriteMode)
-	if c.WriteConfig.RollType == RollBySize {
-		// 按大小滚动
-		writer, writeErr = rollwriter.NewRollWriter(
-			c.WriteConfig.Filename,
-			rollwriter.WithMaxDay(c.WriteConfig.MaxDay),
-			rollwriter.WithMaxHistory(c.WriteConfig.MaxHistory),
-			rollwriter.WithCompress(c.WriteConfig.Compress),
-			rollwriter.WithMaxSize(int64(c.WriteConfig.MaxSize)),
-		)
-		fmt.Printf("[newFileCore]new size writer err:%+v\n", writeErr)
-	} else {
+	opts := []rollwriter.Option{
+		rollwriter.WithMaxDay(c.WriteConfig.MaxDay),
+		rollwriter.WithMaxHistory(c.WriteConfig.MaxHistory),
+		rollwriter.WithMaxTotalSize(int64(c.WriteConfig.MaxTotalSize)),
+		rollwriter.WithMinFreeSpace(int64(c.WriteConfig.MinFreeSpace)),
+		rollwriter.WithCompress(c.WriteConfig.Compress),
+		rollwriter.WithMaxSize(int64(c.WriteConfig.MaxSize)),
+		rollwriter.WithRollType(c.WriteConfig.RollType),
+		rollwriter.WithBackupNaming(c.WriteConfig.BackupNaming),
+		rollwriter.WithBackupTimeFormat(c.WriteConfig.BackupTimeFormat),
+		rollwriter.WithCurrentLink(c.WriteConfig.CurrentLink),
+		rollwriter.WithArchiveDir(c.WriteConfig.ArchiveDir),
+	}
+	if c.WriteConfig.RollType != RollBySize {
 		// 按时间滚动
-		writer, writeErr = rollwriter.NewRollWriter(
-			c.WriteConfig.Filename,
-			rollwriter.WithMaxDay(c.WriteConfig.MaxDay),
-			rollwriter.WithMaxHistory(c.WriteConfig.MaxHistory),
-			rollwriter.WithCompress(c.WriteConfig.Compress),
-			rollwriter.WithMaxSize(int64(c.WriteConfig.MaxSize)),
-			rollwriter.WithTimeFormat(c.WriteConfig.TimeSplit.Format()),
-		)
-		fmt.Printf("[newFileCore]new time writer err:%+v\n", writeErr)
+		opts = append(opts, rollwriter.WithTimeFormat(c.WriteConfig.TimeSplit.Format()))
+	}
+	if c.WriteConfig.ReopenInterval > 0 {
+		opts = append(opts, rollwriter.WithReopenInterval(time.Duration(c.WriteConfig.ReopenInterval)*time.Millisecond))
+	}
+	if c.WriteConfig.Inotify {
+		opts = append(opts, rollwriter.WithInotify(true))
+	}
+	writer, err := rollwriter.NewRollWriter(c.WriteConfig.Filename, opts...)
+	fmt.Printf("[newFileCore]new %s writer err:%+v\n", c.WriteConfig.RollType, err)
+	if err != nil {
+		return nil, zap.AtomicLevel{}, nil, err
 	}
 
 	// 写入模式
+	var closer io.Closer
+	var buffer rollwriter.Syncer
+	var async *rollwriter.AsyncRollWriter
 	if c.WriteConfig.WriteMode == WriteSync { // 如果是同步写入的方式
 		ws = zapcore.AddSync(writer)
+		closer = writer
 	} else {
 		dropLog := (c.WriteConfig.WriteMode == WriteFast)
-		ws = rollwriter.NewAsyncRollWriter(writer,
+		asyncOpts := []rollwriter.AsyncOption{
 			rollwriter.WithCanDropLog(dropLog),
-		)
+			rollwriter.WithOverflowPolicy(c.WriteConfig.OverflowPolicy),
+			rollwriter.WithDropNotice(newDropNotice(c)),
+			rollwriter.WithSpillPath(c.WriteConfig.SpillPath),
+			rollwriter.WithSpillMaxSize(int64(c.WriteConfig.SpillMaxSize)),
+			rollwriter.WithWriteRetry(c.WriteConfig.WriteRetries,
+				time.Duration(c.WriteConfig.RetryBackoff)*time.Millisecond),
+		}
+		if h := getWriteErrorHandler(); h != nil {
+			name := c.Name
+			asyncOpts = append(asyncOpts, rollwriter.WithErrorHandler(func(err error) { h(name, err) }))
+		}
+		if c.WriteConfig.OverflowTimeout > 0 {
+			asyncOpts = append(asyncOpts,
+				rollwriter.WithOverflowTimeout(time.Duration(c.WriteConfig.OverflowTimeout)*time.Millisecond))
+		}
+		async = rollwriter.NewAsyncRollWriter(writer, asyncOpts...)
+		ws = async
+		buffer = async
+		closer = async // 关闭时会关闭下层的RollWriter
 	}
+	// 支持SIGHUP或者ReopenAll重新打开文件
+	rollwriter.Register(writer, buffer)
 
 	// 日志级别
 	lvl := zap.NewAtomicLevelAt(Levels[c.Level])
 
-	return zapcore.NewCore(
-		newEncoder(c),
-		ws, lvl,
-	), lvl
+	var core zapcore.Core
+	if async != nil && c.WriteConfig.OverflowPolicy == rollwriter.OverflowLevel {
+		// 按日志级别决定队列满时是否丢弃
+		core = newLevelCore(newEncoder(c), &asyncOutput{w: async}, lvl)
+	} else {
+		core = zapcore.NewCore(
+			newEncoder(c),
+			ws, lvl,
+		)
+	}
+	if c.WriteConfig.MinFreeSpace > 0 {
+		core = newDiskGuardCore(core, writer, c.WriteConfig.DegradeLevel)
+	}
+	return core, lvl, closer, nil
 }
 
 func newEncoder(cfg *OutputConfig) zapcore.Encoder {
@@ -156,7 +243,7 @@ func newEncoder(cfg *OutputConfig) zapcore.Encoder {
 		CallerKey:      GetLogEncoderKey("C", cfg.FormatConfig.CallerKey),
 		StacktraceKey:  GetLogEncoderKey("S", cfg.FormatConfig.StacktraceKey),
 		LineEnding:     zapcore.DefaultLineEnding,
-		EncodeLevel:    zapcore.CapitalLevelEncoder,
+		EncodeLevel:    CapitalLevelEncoder,
 		EncodeTime:     NewTimeEncoder(cfg.FormatConfig.TimeFmt),
 		EncodeDuration: zapcore.StringDurationEncoder,
 		EncodeCaller:   zapcore.ShortCallerEncoder,
@@ -172,6 +259,15 @@ func newEncoder(cfg *OutputConfig) zapcore.Encoder {
 	}
 }
 
+// CapitalLevelEncoder 大写输出日志级别，在zapcore.CapitalLevelEncoder基础上支持TRACE级别
+func CapitalLevelEncoder(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	if l == zapTraceLevel {
+		enc.AppendString("TRACE")
+		return
+	}
+	zapcore.CapitalLevelEncoder(l, enc)
+}
+
 func GetLogEncoderKey(defaultKey, key string) string {
 	if key == "" {
 		return defaultKey
@@ -233,9 +329,19 @@ func DefaultTimeFormat(t time.Time) []byte {
 	return buf
 }
 
+// zapOutput 输出端信息
+type zapOutput struct {
+	name   string
+	writer string
+	level  zap.AtomicLevel
+	closer io.Closer // 输出端的writer，异步写入时可以获取统计和写入错误
+	core   zapcore.Core
+	conf   OutputConfig // 创建时的配置，热加载时配置没有变化则复用
+}
+
 // zapLog 基于zaplogger的Logger实现
 type zapLog struct {
-	levels []zap.AtomicLevel
+	holder *coreHolder // 支持热加载替换，WithFields生成的logger共享同一个holder
 	logger *zap.Logger
 }
 
@@ -248,20 +354,25 @@ func (l *zapLog) WithFields(fields ...string) Logger {
 	}
 
 	// 使用 ZapLogWrapper 代理，这样返回的 Logger 被调用时，调用栈层数和使用 Debug 系列函数一致，caller 信息能够正确的设置
-	return &ZapLogWrapper{l: &zapLog{logger: l.logger.With(zapfields...)}}
+	return &ZapLogWrapper{l: &zapLog{holder: l.holder, logger: l.logger.With(zapfields...)}}
 }
 
 // Trace logs to TRACE log, Arguments are handled in the manner of fmt.Print
+// zap.Logger没有trace级别的方法，通过Check写入，Check和Debug等方法调用栈层数一致，caller信息不受影响
 func (l *zapLog) Trace(args ...interface{}) {
-	if l.logger.Core().Enabled(zapcore.DebugLevel) {
-		l.logger.Debug(fmt.Sprint(args...))
+	if l.logger.Core().Enabled(zapTraceLevel) {
+		if ce := l.logger.Check(zapTraceLevel, fmt.Sprint(args...)); ce != nil {
+			ce.Write()
+		}
 	}
 }
 
 // Tracef logs to TRACE log, Arguments are handled in the manner of fmt.Printf
 func (l *zapLog) Tracef(format string, args ...interface{}) {
-	if l.logger.Core().Enabled(zapcore.DebugLevel) {
-		l.logger.Debug(fmt.Sprintf(format, args...))
+	if l.logger.Core().Enabled(zapTraceLevel) {
+		if ce := l.logger.Check(zapTraceLevel, fmt.Sprintf(format, args...)); ce != nil {
+			ce.Write()
+		}
 	}
 }
 
@@ -335,34 +446,201 @@ func (l *zapLog) Fatalf(format string, args ...interface{}) {
 	}
 }
 
+// Tracew logs to TRACE log, keysAndValues are handled as structured fields
+func (l *zapLog) Tracew(msg string, keysAndValues ...interface{}) {
+	if l.logger.Core().Enabled(zapTraceLevel) {
+		if ce := l.logger.Check(zapTraceLevel, msg); ce != nil {
+			ce.Write(toZapFields(keysAndValues)...)
+		}
+	}
+}
+
+// Debugw logs to DEBUG log, keysAndValues are handled as structured fields
+func (l *zapLog) Debugw(msg string, keysAndValues ...interface{}) {
+	if l.logger.Core().Enabled(zapcore.DebugLevel) {
+		l.logger.Debug(msg, toZapFields(keysAndValues)...)
+	}
+}
+
+// Infow logs to INFO log, keysAndValues are handled as structured fields
+func (l *zapLog) Infow(msg string, keysAndValues ...interface{}) {
+	if l.logger.Core().Enabled(zapcore.InfoLevel) {
+		l.logger.Info(msg, toZapFields(keysAndValues)...)
+	}
+}
+
+// Warnw logs to WARNING log, keysAndValues are handled as structured fields
+func (l *zapLog) Warnw(msg string, keysAndValues ...interface{}) {
+	if l.logger.Core().Enabled(zapcore.WarnLevel) {
+		l.logger.Warn(msg, toZapFields(keysAndValues)...)
+	}
+}
+
+// Errorw logs to ERROR log, keysAndValues are handled as structured fields
+func (l *zapLog) Errorw(msg string, keysAndValues ...interface{}) {
+	if l.logger.Core().Enabled(zapcore.ErrorLevel) {
+		l.logger.Error(msg, toZapFields(keysAndValues)...)
+	}
+}
+
+// Fatalw logs to FATAL log, keysAndValues are handled as structured fields
+func (l *zapLog) Fatalw(msg string, keysAndValues ...interface{}) {
+	if l.logger.Core().Enabled(zapcore.FatalLevel) {
+		l.logger.Fatal(msg, toZapFields(keysAndValues)...)
+	}
+}
+
+// toZapFields 将kv对转换成zap的强类型字段
+// 已经是zap.Field的参数直接使用，key不是string时转成字符串，落单的value使用"!BADKEY"作为key
+func toZapFields(keysAndValues []interface{}) []zap.Field {
+	if len(keysAndValues) == 0 {
+		return nil
+	}
+
+	fields := make([]zap.Field, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); {
+		if f, ok := keysAndValues[i].(zap.Field); ok {
+			fields = append(fields, f)
+			i++
+			continue
+		}
+
+		if i == len(keysAndValues)-1 {
+			fields = append(fields, zap.Any("!BADKEY", keysAndValues[i]))
+			break
+		}
+
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		fields = append(fields, zap.Any(key, keysAndValues[i+1]))
+		i += 2
+	}
+	return fields
+}
+
 // Sync calls the zap logger's Sync method, flushing any buffered log entries.
 // Applications should take care to call Sync before exiting.
 func (l *zapLog) Sync() error {
 	return l.logger.Sync()
 }
 
-// SetLevel 设置输出端日志级别
+// SetLevel 设置输出端日志级别，output为输出端名称或者下标
 func (l *zapLog) SetLevel(output string, level Level) {
-	i, e := strconv.Atoi(output)
-	if e != nil {
+	o, ok := l.findOutput(output)
+	if !ok {
 		return
 	}
-	if i < 0 || i >= len(l.levels) {
-		return
-	}
-	l.levels[i].SetLevel(levelToZapLevel[level])
+	o.level.SetLevel(levelToZapLevel[level])
 }
 
-// GetLevel 获取输出端日志级别
+// GetLevel 获取输出端日志级别，output为输出端名称或者下标
 func (l *zapLog) GetLevel(output string) Level {
+	o, ok := l.findOutput(output)
+	if !ok {
+		return LevelDebug
+	}
+	return zapLevelToLevel[o.level.Level()]
+}
+
+// Levels 获取所有输出端的名称、类型和日志级别
+func (l *zapLog) Levels() []OutputLevel {
+	outputs := l.holder.load().outputs
+	levels := make([]OutputLevel, 0, len(outputs))
+	for _, o := range outputs {
+		levels = append(levels, OutputLevel{
+			Name:   o.name,
+			Writer: o.writer,
+			Level:  zapLevelToLevel[o.level.Level()],
+		})
+	}
+	return levels
+}
+
+// Stats 获取异步写入的输出端的写入和丢弃统计
+func (l *zapLog) Stats() []OutputStats {
+	outputs := l.holder.load().outputs
+	stats := make([]OutputStats, 0, len(outputs))
+	for _, o := range outputs {
+		w, ok := o.closer.(statsWriter)
+		if !ok {
+			continue
+		}
+		st := w.Stats()
+		stats = append(stats, OutputStats{
+			Name:         o.name,
+			Writer:       o.writer,
+			Accepted:     st.Accepted,
+			Dropped:      st.Dropped,
+			BytesWritten: st.BytesWritten,
+			Flushes:      st.Flushes,
+			Spilled:      st.Spilled,
+		})
+	}
+	return stats
+}
+
+// Errors 获取异步写入的输出端最近一次写入失败的时间和错误
+func (l *zapLog) Errors() []OutputError {
+	outputs := l.holder.load().outputs
+	errs := make([]OutputError, 0, len(outputs))
+	for _, o := range outputs {
+		w, ok := o.closer.(lastErrorWriter)
+		if !ok {
+			continue
+		}
+		at, err := w.LastError()
+		errs = append(errs, OutputError{
+			Name:   o.name,
+			Writer: o.writer,
+			At:     at,
+			Err:    err,
+		})
+	}
+	return errs
+}
+
+// findOutput 根据名称查找输出端，找不到时把output当作下标，兼容旧的下标用法
+func (l *zapLog) findOutput(output string) (zapOutput, bool) {
+	outputs := l.holder.load().outputs
+	for _, o := range outputs {
+		if o.name == output {
+			return o, true
+		}
+	}
+
 	i, e := strconv.Atoi(output)
 	if e != nil {
-		return LevelDebug
+		return zapOutput{}, false
 	}
-	if i < 0 || i >= len(l.levels) {
-		return LevelDebug
+	if i < 0 || i >= len(outputs) {
+		return zapOutput{}, false
 	}
-	return zapLevelToLevel[l.levels[i].Level()]
+	return outputs[i], true
+}
+
+// Reload 使用新的配置替换所有输出端，WithFields生成的logger同时生效
+// 配置没有变化的输出端直接复用，保留运行时修改的级别，其他被替换的输出端会刷盘并关闭
+// callerSkip在创建时已经确定，不随配置变化
+func (l *zapLog) Reload(c Config) error {
+	if len(c) == 0 {
+		return errors.New("log config output empty")
+	}
+
+	l.holder.mu.Lock()
+	defer l.holder.mu.Unlock()
+
+	state, err := newCoreState(c, l.holder.load())
+	if err != nil {
+		return err
+	}
+
+	old := l.holder.swap(state)
+	if old != nil {
+		old.release(state)
+	}
+	return nil
 }
 
 type ZapLogWrapper struct {
@@ -434,6 +712,36 @@ func (z *ZapLogWrapper) Fatalf(format string, args ...interface{}) {
 	z.l.Fatalf(format, args...)
 }
 
+// Tracew logs to TRACE log, keysAndValues are handled as structured fields
+func (z *ZapLogWrapper) Tracew(msg string, keysAndValues ...interface{}) {
+	z.l.Tracew(msg, keysAndValues...)
+}
+
+// Debugw logs to DEBUG log, keysAndValues are handled as structured fields
+func (z *ZapLogWrapper) Debugw(msg string, keysAndValues ...interface{}) {
+	z.l.Debugw(msg, keysAndValues...)
+}
+
+// Infow logs to INFO log, keysAndValues are handled as structured fields
+func (z *ZapLogWrapper) Infow(msg string, keysAndValues ...interface{}) {
+	z.l.Infow(msg, keysAndValues...)
+}
+
+// Warnw logs to WARNING log, keysAndValues are handled as structured fields
+func (z *ZapLogWrapper) Warnw(msg string, keysAndValues ...interface{}) {
+	z.l.Warnw(msg, keysAndValues...)
+}
+
+// Errorw logs to ERROR log, keysAndValues are handled as structured fields
+func (z *ZapLogWrapper) Errorw(msg string, keysAndValues ...interface{}) {
+	z.l.Errorw(msg, keysAndValues...)
+}
+
+// Fatalw logs to FATAL log, keysAndValues are handled as structured fields
+func (z *ZapLogWrapper) Fatalw(msg string, keysAndValues ...interface{}) {
+	z.l.Fatalw(msg, keysAndValues...)
+}
+
 // Sync calls the zap logger's Sync method, flushing any buffered log entries.
 // Applications should take care to call Sync before exiting.
 func (z *ZapLogWrapper) Sync() error {
@@ -450,6 +758,21 @@ func (z *ZapLogWrapper) GetLevel(output string) Level {
 	return z.l.GetLevel(output)
 }
 
+// Levels 获取所有输出端的名称、类型和日志级别
+func (z *ZapLogWrapper) Levels() []OutputLevel {
+	return z.l.Levels()
+}
+
+// Stats 获取异步写入的输出端的写入和丢弃统计
+func (z *ZapLogWrapper) Stats() []OutputStats {
+	return z.l.Stats()
+}
+
+// Errors 获取异步写入的输出端最近一次写入失败的时间和错误
+func (z *ZapLogWrapper) Errors() []OutputError {
+	return z.l.Errors()
+}
+
 // WithFields 设置一些业务自定义数据到每条log里:比如uid，imei等, 每个请求入口设置，并生成一个新的logger，后续使用新的logger来打日志 fields 必须kv成对出现
 func (z *ZapLogWrapper) WithFields(fields ...string) Logger {
 	return z.l.WithFields(fields...)
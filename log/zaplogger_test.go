@@ -0,0 +1,155 @@
+package log
+
+import (
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestToZapFields(t *testing.T) {
+	l, buf := newTestLogger(t, "debug")
+	sl, ok := l.(StructuredLogger)
+	if !ok {
+		t.Fatal("zap logger should implement StructuredLogger")
+	}
+
+	tests := []struct {
+		keysAndValues []interface{}
+		want          map[string]interface{}
+	}{
+		{nil, map[string]interface{}{}},
+		{[]interface{}{"uid", "10001", "cost", 3}, map[string]interface{}{"uid": "10001", "cost": float64(3)}},
+		{[]interface{}{1, "int key", true, "bool key"}, map[string]interface{}{"1": "int key", "true": "bool key"}},
+		{[]interface{}{"uid", "10001", "odd"}, map[string]interface{}{"uid": "10001", "!BADKEY": "odd"}},
+		{[]interface{}{zap.Int("n", 1), "uid", "10001"}, map[string]interface{}{"n": float64(1), "uid": "10001"}},
+	}
+	for _, tt := range tests {
+		sl.Infow("msg", tt.keysAndValues...)
+	}
+
+	entries := buf.entries(t)
+	if len(entries) != len(tests) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(tests))
+	}
+	for i, tt := range tests {
+		got := make(map[string]interface{})
+		for k, v := range entries[i] {
+			switch k {
+			case "T", "L", "C", "M":
+			default:
+				got[k] = v
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v got fields %v, want %v", tt.keysAndValues, got, tt.want)
+		}
+	}
+}
+
+// plainLogger 不支持结构化日志的Logger
+type plainLogger struct {
+	Logger
+}
+
+func TestStructuredFallback(t *testing.T) {
+	l, buf := newTestLogger(t, "debug")
+
+	restore := setDefaultLogger(l)
+	Infow("structured", "uid", "10001")
+	restore()
+
+	restore = setDefaultLogger(plainLogger{l})
+	Infow("plain", "uid", "10001", "odd")
+	restore()
+
+	entries := buf.entries(t)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if e := entries[0]; e["M"] != "structured" || e["uid"] != "10001" {
+		t.Errorf("structured logger got %v", e)
+	}
+	if e := entries[1]; e["M"] != "plain uid=10001 odd" || e["uid"] != nil {
+		t.Errorf("plain logger got %v", e)
+	}
+}
+
+func TestTraceLevel(t *testing.T) {
+	tests := []struct {
+		level string
+		want  []string
+	}{
+		{"trace", []string{"TRACE", "TRACE", "DEBUG"}},
+		{"debug", []string{"DEBUG"}},
+		{"info", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			l, buf := newTestLogger(t, tt.level)
+			l.Trace("trace")
+			l.Tracef("trace %d", 1)
+			l.Debug("debug")
+
+			got := make([]string, 0)
+			for _, e := range buf.entries(t) {
+				got = append(got, e["L"].(string))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("level %s got %v, want %v", tt.level, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetTraceLevel(t *testing.T) {
+	l, buf := newTestLogger(t, "debug")
+	l.Trace("disabled")
+	l.SetLevel("0", LevelTrace)
+	l.Trace("enabled")
+
+	if got := buf.messages(t); !reflect.DeepEqual(got, []string{"enabled"}) {
+		t.Errorf("got %v, want [enabled]", got)
+	}
+	if lv := l.GetLevel("0"); lv != LevelTrace {
+		t.Errorf("GetLevel %s, want trace", lv.String())
+	}
+}
+
+func TestSetLevelByName(t *testing.T) {
+	name := t.Name()
+	named := testOutputConfig(name+"/error", "error")
+	named.Name = "file_error"
+	l := NewZapLog(Config{testOutputConfig(name+"/0", "info"), named})
+
+	tests := []struct {
+		output string
+		level  Level
+		want   []Level // 设置后两个输出端的级别
+	}{
+		{"0", LevelDebug, []Level{LevelDebug, LevelError}},
+		{"file_error", LevelWarn, []Level{LevelDebug, LevelWarn}},
+		{"1", LevelInfo, []Level{LevelDebug, LevelInfo}},
+		{"unknown", LevelTrace, []Level{LevelDebug, LevelInfo}},
+		{"2", LevelTrace, []Level{LevelDebug, LevelInfo}},
+		{"-1", LevelTrace, []Level{LevelDebug, LevelInfo}},
+	}
+	for _, tt := range tests {
+		l.SetLevel(tt.output, tt.level)
+		got := []Level{l.GetLevel("0"), l.GetLevel("file_error")}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SetLevel %s got %v, want %v", tt.output, got, tt.want)
+		}
+	}
+
+	want := []OutputLevel{
+		{Name: "0", Writer: testWriter, Level: LevelDebug},
+		{Name: "file_error", Writer: testWriter, Level: LevelInfo},
+	}
+	if got := l.Levels(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Levels got %v, want %v", got, want)
+	}
+	if lv := l.GetLevel("unknown"); lv != LevelDebug {
+		t.Errorf("GetLevel of unknown output %s, want debug", lv.String())
+	}
+}